@@ -0,0 +1,454 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Tüm aksiyon tiplerinde ortak, opsiyonel alanlar
+type ActionBase struct {
+	Priority  *int       `yaml:"priority,omitempty" json:"priority,omitempty"`
+	Condition *Condition `yaml:"condition,omitempty" json:"condition,omitempty"`
+}
+
+// Condition: aksiyonun uygulanması için sağlanması gereken koşullar
+// (frontend'deki checkCondition ile aynı anahtarlar)
+type Condition struct {
+	URL               string            `yaml:"url,omitempty" json:"url,omitempty"`
+	Host              string            `yaml:"host,omitempty" json:"host,omitempty"`
+	UserAgentIncludes string            `yaml:"userAgentIncludes,omitempty" json:"userAgentIncludes,omitempty"`
+	IsLoggedIn        *bool             `yaml:"isLoggedIn,omitempty" json:"isLoggedIn,omitempty"`
+	QueryParam        map[string]string `yaml:"queryParam,omitempty" json:"queryParam,omitempty"`
+	LocalStorage      map[string]string `yaml:"localStorage,omitempty" json:"localStorage,omitempty"`
+	Cookie            map[string]string `yaml:"cookie,omitempty" json:"cookie,omitempty"`
+}
+
+func (c *Condition) clone() *Condition {
+	if c == nil {
+		return nil
+	}
+	out := *c
+	if c.IsLoggedIn != nil {
+		v := *c.IsLoggedIn
+		out.IsLoggedIn = &v
+	}
+	for _, m := range []*map[string]string{&out.QueryParam, &out.LocalStorage, &out.Cookie} {
+		if *m == nil {
+			continue
+		}
+		cp := make(map[string]string, len(*m))
+		for k, v := range *m {
+			cp[k] = v
+		}
+		*m = cp
+	}
+	return &out
+}
+
+// Action: remove, replace, insert veya alter aksiyonlarından biri
+type Action interface {
+	ActionType() string
+	base() *ActionBase
+	// check, zorunlu alanları, enum değerlerini ve selector'ları denetler
+	check() actionErrors
+}
+
+// Seçilen elementleri kaldırır
+type RemoveAction struct {
+	Type       string `yaml:"type" json:"type"`
+	Selector   string `yaml:"selector" json:"selector"`
+	ActionBase `yaml:",inline"`
+}
+
+// Seçilen elementleri newElement ile değiştirir
+type ReplaceAction struct {
+	Type       string `yaml:"type" json:"type"`
+	Selector   string `yaml:"selector" json:"selector"`
+	NewElement string `yaml:"newElement" json:"newElement"`
+	ActionBase `yaml:",inline"`
+}
+
+// target'a göre position konumuna element ekler
+type InsertAction struct {
+	Type       string `yaml:"type" json:"type"`
+	Position   string `yaml:"position" json:"position"`
+	Target     string `yaml:"target" json:"target"`
+	Element    string `yaml:"element" json:"element"`
+	ActionBase `yaml:",inline"`
+}
+
+// Sayfadaki metinlerde oldValue'yu newValue ile değiştirir
+type AlterAction struct {
+	Type          string `yaml:"type" json:"type"`
+	OldValue      string `yaml:"oldValue" json:"oldValue"`
+	NewValue      string `yaml:"newValue" json:"newValue"`
+	CaseSensitive *bool  `yaml:"caseSensitive,omitempty" json:"caseSensitive,omitempty"`
+	// true ise oldValue bir RegExp desenidir (bkz. checkAlterRegex)
+	Regex      *bool `yaml:"regex,omitempty" json:"regex,omitempty"`
+	ActionBase `yaml:",inline"`
+}
+
+func (a *RemoveAction) ActionType() string  { return "remove" }
+func (a *ReplaceAction) ActionType() string { return "replace" }
+func (a *InsertAction) ActionType() string  { return "insert" }
+func (a *AlterAction) ActionType() string   { return "alter" }
+
+func (a *RemoveAction) base() *ActionBase  { return &a.ActionBase }
+func (a *ReplaceAction) base() *ActionBase { return &a.ActionBase }
+func (a *InsertAction) base() *ActionBase  { return &a.ActionBase }
+func (a *AlterAction) base() *ActionBase   { return &a.ActionBase }
+
+// insert aksiyonunda kabul edilen position değerleri
+var insertPositions = []string{"before", "after", "prepend", "append"}
+
+// Boş bırakılmaması gereken metin alanı
+func requireField(errs *actionErrors, field, value string) bool {
+	if strings.TrimSpace(value) == "" {
+		*errs = append(*errs, &actionError{Field: field, Code: codeRequired, Message: "zorunlu alan"})
+		return false
+	}
+	return true
+}
+
+// Zorunlu ve geçerli bir CSS selector olması gereken alan
+func requireSelector(errs *actionErrors, field, value string) {
+	if !requireField(errs, field, value) {
+		return
+	}
+	if _, err := ParseSelector(value); err != nil {
+		*errs = append(*errs, &actionError{Field: field, Code: codeInvalidSelector, Message: "geçersiz CSS selector: " + err.Error()})
+	}
+}
+
+func (a *RemoveAction) check() actionErrors {
+	var errs actionErrors
+	requireSelector(&errs, "selector", a.Selector)
+	return errs
+}
+
+func (a *ReplaceAction) check() actionErrors {
+	var errs actionErrors
+	requireSelector(&errs, "selector", a.Selector)
+	requireField(&errs, "newElement", a.NewElement)
+	return errs
+}
+
+func (a *InsertAction) check() actionErrors {
+	var errs actionErrors
+	requireSelector(&errs, "target", a.Target)
+	requireField(&errs, "element", a.Element)
+	if requireField(&errs, "position", a.Position) && !containsString(insertPositions, a.Position) {
+		errs = append(errs, &actionError{Field: "position", Code: codeInvalidEnum, Message: enumMessage(insertPositions)})
+	}
+	return errs
+}
+
+// type alanına göre boş aksiyon; bilinmeyen tipte nil
+func newAction(typ string) Action {
+	switch typ {
+	case "remove":
+		return &RemoveAction{Type: typ}
+	case "replace":
+		return &ReplaceAction{Type: typ}
+	case "insert":
+		return &InsertAction{Type: typ}
+	case "alter":
+		return &AlterAction{Type: typ}
+	}
+	return nil
+}
+
+// actionError: tek bir aksiyondaki tek bir sorun. Index, aksiyonun listedeki sırası;
+// liste düzeyindeki sorunlarda -1.
+type actionError struct {
+	Index   int
+	Field   string
+	Code    string
+	Message string
+}
+
+// JSON yolu, ör. actions[3].selector
+func (e *actionError) path() string {
+	if e.Index < 0 {
+		return "actions"
+	}
+	if e.Field == "" {
+		return fmt.Sprintf("actions[%d]", e.Index)
+	}
+	return fmt.Sprintf("actions[%d].%s", e.Index, e.Field)
+}
+
+func (e *actionError) Error() string {
+	return e.path() + ": " + e.Message
+}
+
+// actionErrors: bir aksiyon listesinde bulunan tüm sorunlar
+type actionErrors []*actionError
+
+func (errs actionErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (errs actionErrors) validationErrors() ValidationErrors {
+	out := make(ValidationErrors, len(errs))
+	for i, e := range errs {
+		out[i] = ValidationError{Path: e.path(), Code: e.Code, Message: e.Message}
+	}
+	return out
+}
+
+// Actions: aksiyon listesi. JSON ve YAML'den type alanına göre ilgili struct'a,
+// bilinmeyen alanları ve yanlış tipleri reddederek çözülür.
+type Actions []Action
+
+func (a *Actions) UnmarshalJSON(b []byte) error {
+	var raws []json.RawMessage
+	if err := json.Unmarshal(b, &raws); err != nil {
+		return actionErrors{{Index: -1, Code: codeInvalidType, Message: "liste olmalı"}}
+	}
+	return a.decode(len(raws), func(i int) (map[string]fieldDecoder, error) {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(raws[i], &fields); err != nil || fields == nil {
+			return nil, fmt.Errorf("aksiyon bir nesne olmalı")
+		}
+		out := make(map[string]fieldDecoder, len(fields))
+		for k, raw := range fields {
+			raw := raw
+			out[k] = func(v interface{}) error { return json.Unmarshal(raw, v) }
+		}
+		return out, nil
+	})
+}
+
+func (a *Actions) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.SequenceNode {
+		return actionErrors{{Index: -1, Code: codeInvalidType, Message: "liste olmalı"}}
+	}
+	return a.decode(len(node.Content), yamlActionFields(node))
+}
+
+func yamlActionFields(node *yaml.Node) func(i int) (map[string]fieldDecoder, error) {
+	return func(i int) (map[string]fieldDecoder, error) {
+		item := node.Content[i]
+		if item.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("aksiyon bir nesne olmalı")
+		}
+		out := make(map[string]fieldDecoder, len(item.Content)/2)
+		for j := 0; j+1 < len(item.Content); j += 2 {
+			value := item.Content[j+1]
+			out[item.Content[j].Value] = value.Decode
+		}
+		return out, nil
+	}
+}
+
+// Kayıtlı aksiyonları okurken kullanılır: kurallar sonradan sıkılaşmış olabileceğinden
+// şema ve check() sorunları hata değil rapordur. Çözülebilen aksiyonlar (hatalı alanları
+// boş kalarak) döner; tipi bilinmeyen ya da nesne olmayan öğe listeye alınmaz.
+func decodeStoredActions(node *yaml.Node) (Actions, ValidationErrors) {
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return nil, nil
+	}
+	if node.Kind != yaml.SequenceNode {
+		return nil, actionErrors{{Index: -1, Code: codeInvalidType, Message: "liste olmalı"}}.validationErrors()
+	}
+	out, errs := decodeActionList(len(node.Content), yamlActionFields(node))
+	if len(errs) > 0 {
+		return out, errs.validationErrors()
+	}
+	return out, nil
+}
+
+// Tek bir alanın değerini verilen hedefe çözen fonksiyon (JSON veya YAML)
+type fieldDecoder func(v interface{}) error
+
+// Her aksiyonu alan alan çözer ki tüm sorunlar tek seferde raporlanabilsin
+func (a *Actions) decode(n int, fieldsAt func(i int) (map[string]fieldDecoder, error)) error {
+	out, errs := decodeActionList(n, fieldsAt)
+	if len(errs) > 0 {
+		return errs
+	}
+	*a = out
+	return nil
+}
+
+func decodeActionList(n int, fieldsAt func(i int) (map[string]fieldDecoder, error)) (Actions, actionErrors) {
+	out := make(Actions, 0, n)
+	var errs actionErrors
+	for i := 0; i < n; i++ {
+		fields, err := fieldsAt(i)
+		if err != nil {
+			errs = append(errs, &actionError{Index: i, Code: codeInvalidType, Message: err.Error()})
+			continue
+		}
+		act, actErrs := decodeAction(fields)
+		for _, e := range actErrs {
+			e.Index = i
+		}
+		errs = append(errs, actErrs...)
+		if act != nil {
+			out = append(out, act)
+		}
+	}
+	return out, errs
+}
+
+// Aksiyonun alanlarını önce şemaya (bkz. schema.go) göre denetler, sonra tipli struct'a çözer
+// ve check() ile selector, regex gibi şemanın ifade edemediği kuralları uygular
+func decodeAction(fields map[string]fieldDecoder) (Action, actionErrors) {
+	raw := make(map[string]interface{}, len(fields))
+	for k, decode := range fields {
+		var v interface{}
+		if err := decode(&v); err != nil {
+			return nil, actionErrors{{Field: k, Code: codeInvalidType, Message: "geçersiz değer"}}
+		}
+		raw[k] = v
+	}
+	var errs actionErrors
+	failed := map[string]bool{}
+	for _, e := range validateSchema(schemaDefs["action"], raw, "") {
+		errs = append(errs, &actionError{Field: e.Path, Code: e.Code, Message: e.Message})
+		failed[strings.FieldsFunc(e.Path, func(r rune) bool { return r == '.' || r == '[' })[0]] = true
+	}
+	act := newAction(fmt.Sprint(raw["type"]))
+	if act == nil || failed["type"] {
+		return nil, errs
+	}
+
+	targets := actionFields(act)
+	for k := range fields {
+		if k == "type" || failed[k] {
+			continue
+		}
+		if k == "condition" {
+			// Şemadan geçmiş koşul, Condition ile aynı anahtarları taşır
+			b, _ := json.Marshal(raw[k])
+			act.base().Condition = &Condition{}
+			json.Unmarshal(b, act.base().Condition)
+			continue
+		}
+		if err := fields[k](targets[k].Addr().Interface()); err != nil {
+			// Şemanın kabul edip Go tipinin almadığı değer (ör. JSON'da 1.0 tam sayı alanına)
+			errs = append(errs, &actionError{Field: k, Code: codeInvalidType, Message: schemaTypeMessage(schemaDefs["action_"+act.ActionType()].Properties[k])})
+			failed[k] = true
+		}
+	}
+	// Şemada hatalı çıkan alanlar için check() ayrıca raporlanmaz
+	for _, e := range act.check() {
+		if !failed[e.Field] {
+			errs = append(errs, e)
+		}
+	}
+	return act, errs
+}
+
+// Aksiyon struct'ının (gömülü ActionBase dahil) json adına göre alanları
+func actionFields(act Action) map[string]reflect.Value {
+	out := map[string]reflect.Value{}
+	var walk func(v reflect.Value)
+	walk = func(v reflect.Value) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.Anonymous {
+				walk(v.Field(i))
+				continue
+			}
+			name := strings.Split(f.Tag.Get("json"), ",")[0]
+			if name != "" && name != "-" {
+				out[name] = v.Field(i)
+			}
+		}
+	}
+	walk(reflect.ValueOf(act).Elem())
+	return out
+}
+
+// Genel/spesifik config'teki serbest biçimli actions değerini tiplere çöz
+func decodeActions(raw interface{}) (Actions, error) {
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var actions Actions
+	if err := json.Unmarshal(b, &actions); err != nil {
+		return nil, err
+	}
+	return actions, nil
+}
+
+// Genel/spesifik config'in actions alanını tiplere çözer, doğrular, config'in politikasıyla
+// sanitize eder ve config'e tipli listeyi geri yazar. Politika store'dan okunamazsa
+// dönen hata doğrulama hatası değildir.
+func prepareConfigActions(cfg Config, strict bool) ([]ActionSanitizeReport, error) {
+	raw, ok := cfg["actions"]
+	if !ok || raw == nil {
+		return nil, actionErrors{{Index: -1, Code: codeRequired, Message: "zorunlu alan"}}
+	}
+	sanitizer, err := configSanitizer(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var report []ActionSanitizeReport
+	actions, err := decodeActions(raw)
+	if err == nil {
+		report, err = validateAndSanitizeActions(actions, sanitizer, strict)
+	}
+	if err != nil {
+		if _, ok := asValidationErrors(err); !ok {
+			return nil, actionErrors{{Index: -1, Code: codeInvalidType, Message: err.Error()}}
+		}
+		return nil, err
+	}
+	cfg["actions"] = actions
+	return report, nil
+}
+
+// Derin kopya (cache'teki nesnenin çağıran tarafından değiştirilmemesi için)
+func (a Actions) clone() Actions {
+	if a == nil {
+		return nil
+	}
+	out := make(Actions, len(a))
+	for i, act := range a {
+		var c Action
+		switch t := act.(type) {
+		case *RemoveAction:
+			cp := *t
+			c = &cp
+		case *ReplaceAction:
+			cp := *t
+			c = &cp
+		case *InsertAction:
+			cp := *t
+			c = &cp
+		case *AlterAction:
+			cp := *t
+			if t.CaseSensitive != nil {
+				v := *t.CaseSensitive
+				cp.CaseSensitive = &v
+			}
+			if t.Regex != nil {
+				v := *t.Regex
+				cp.Regex = &v
+			}
+			c = &cp
+		}
+		b := c.base()
+		if b.Priority != nil {
+			p := *b.Priority
+			b.Priority = &p
+		}
+		b.Condition = b.Condition.clone()
+		out[i] = c
+	}
+	return out
+}
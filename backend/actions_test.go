@@ -0,0 +1,291 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestActionsDecodeTyped(t *testing.T) {
+	src := `[
+		{"type": "remove", "selector": ".ad", "priority": 10, "condition": {"url": "/post/"}},
+		{"type": "replace", "selector": "#h", "newElement": "<header>x</header>"},
+		{"type": "insert", "position": "after", "target": "body", "element": "<footer>f</footer>"},
+		{"type": "alter", "oldValue": "a", "newValue": "b", "caseSensitive": false}
+	]`
+	var fromJSON Actions
+	if err := json.Unmarshal([]byte(src), &fromJSON); err != nil {
+		t.Fatal(err)
+	}
+	var fromYAML Actions
+	if err := yaml.Unmarshal([]byte(src), &fromYAML); err != nil {
+		t.Fatal(err)
+	}
+	for _, actions := range []Actions{fromJSON, fromYAML} {
+		if len(actions) != 4 {
+			t.Fatalf("decoded %d actions", len(actions))
+		}
+		rm, ok := actions[0].(*RemoveAction)
+		if !ok || rm.Selector != ".ad" || rm.Priority == nil || *rm.Priority != 10 || rm.Condition == nil || rm.Condition.URL != "/post/" {
+			t.Errorf("remove = %+v", actions[0])
+		}
+		if ins, ok := actions[2].(*InsertAction); !ok || ins.Position != "after" || ins.Target != "body" {
+			t.Errorf("insert = %+v", actions[2])
+		}
+		if alt, ok := actions[3].(*AlterAction); !ok || alt.CaseSensitive == nil || *alt.CaseSensitive {
+			t.Errorf("alter = %+v", actions[3])
+		}
+	}
+
+	// YAML'e yazılıp geri okununca aynı kalmalı
+	b, err := yaml.Marshal(fromJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var again Actions
+	if err := yaml.Unmarshal(b, &again); err != nil {
+		t.Fatalf("round trip: %v\n%s", err, b)
+	}
+	j1, _ := json.Marshal(fromJSON)
+	j2, _ := json.Marshal(again)
+	if string(j1) != string(j2) {
+		t.Errorf("round trip mismatch:\n%s\n%s", j1, j2)
+	}
+}
+
+func TestActionsDecodeRejectsBadInput(t *testing.T) {
+	src := `[
+		{"type": "remove", "selector": ".ad", "priority": "high"},
+		{"type": "replace", "selector": "#h", "newElemnt": "<b>x</b>"},
+		{"selector": ".x"},
+		{"type": "hide", "selector": ".x"},
+		{"type": "alter", "oldValue": "a", "caseSensitive": "maybe"},
+		"remove"
+	]`
+	want := []string{
+		"actions[0].priority",
+		"actions[1].newElemnt",
+		"actions[1].newElement",
+		"actions[2].type",
+		"actions[3].type",
+		"actions[4].caseSensitive",
+		"actions[4].newValue",
+		"actions[5]",
+	}
+	decoders := map[string]func(*Actions) error{
+		"json": func(a *Actions) error { return json.Unmarshal([]byte(src), a) },
+		"yaml": func(a *Actions) error { return yaml.Unmarshal([]byte(src), a) },
+	}
+	for name, decode := range decoders {
+		var actions Actions
+		err := decode(&actions)
+		var errs actionErrors
+		if !errors.As(err, &errs) {
+			t.Fatalf("%s: want actionErrors, got %v", name, err)
+		}
+		if len(errs) != len(want) {
+			t.Errorf("%s: got %d errors, want %d: %v", name, len(errs), len(want), err)
+			continue
+		}
+		for i, e := range errs {
+			if !strings.HasPrefix(e.Error(), want[i]) {
+				t.Errorf("%s: error %d = %q, want prefix %q", name, i, e.Error(), want[i])
+			}
+		}
+	}
+}
+
+func TestActionsRequiredFieldsAndEnums(t *testing.T) {
+	tests := []struct {
+		action string
+		errors []string
+	}{
+		{`{"type": "remove", "selector": ".ad"}`, nil},
+		{`{"type": "remove"}`, []string{"selector: zorunlu alan"}},
+		{`{"type": "remove", "selector": "  "}`, []string{"selector: zorunlu alan"}},
+		{`{"type": "remove", "selector": "<b>"}`, []string{"selector: geçersiz CSS selector: 1. karakterde selector bekleniyor, '<' bulundu"}},
+		{`{"type": "replace", "selector": "#h"}`, []string{"newElement: zorunlu alan"}},
+		{`{"type": "replace", "newElement": "<b>x</b>"}`, []string{"selector: zorunlu alan"}},
+		{`{"type": "insert", "position": "after", "target": "body", "element": "<b>x</b>"}`, nil},
+		{`{"type": "insert", "position": "inside", "target": "body", "element": "<b>x</b>"}`, []string{"position: before, after, prepend veya append olmalı"}},
+		{`{"type": "insert"}`, []string{"target: zorunlu alan", "element: zorunlu alan", "position: zorunlu alan"}},
+		{`{"type": "alter", "newValue": "b"}`, []string{"oldValue: zorunlu alan"}},
+		{`{"type": "alter", "oldValue": "a", "newValue": "b", "caseSensitive": 1}`, []string{"caseSensitive: true/false olmalı"}},
+		{`{"type": "remove", "selector": ".ad", "priority": 1.5}`, []string{"priority: tam sayı olmalı"}},
+	}
+	for _, tt := range tests {
+		var actions Actions
+		err := json.Unmarshal([]byte("["+tt.action+"]"), &actions)
+		var errs actionErrors
+		errors.As(err, &errs)
+		if len(errs) != len(tt.errors) {
+			t.Errorf("%s: errors = %v, want %v", tt.action, err, tt.errors)
+			continue
+		}
+		for i, e := range errs {
+			if want := "actions[0]." + tt.errors[i]; e.Error() != want {
+				t.Errorf("%s: error %d = %q, want %q", tt.action, i, e.Error(), want)
+			}
+		}
+	}
+}
+
+func TestActionConditionValidation(t *testing.T) {
+	src := `[{"type": "remove", "selector": ".ad", "condition": {
+		"url": "/post/", "host": "blog.example.com", "userAgentIncludes": "Mobile", "isLoggedIn": true,
+		"queryParam": {"utm_source": "google"}, "localStorage": {"theme": "dark"}, "cookie": {"session": "active"}
+	}}]`
+	for name, unmarshal := range map[string]func([]byte, interface{}) error{"json": json.Unmarshal, "yaml": yaml.Unmarshal} {
+		var actions Actions
+		if err := unmarshal([]byte(src), &actions); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		c := actions[0].(*RemoveAction).Condition
+		if c == nil || c.URL != "/post/" || c.IsLoggedIn == nil || !*c.IsLoggedIn || c.QueryParam["utm_source"] != "google" || c.Cookie["session"] != "active" {
+			t.Errorf("%s: condition = %+v", name, c)
+		}
+	}
+
+	bad := `[{"type": "remove", "selector": ".ad", "condition": {
+		"urll": "/post/", "isLoggedIn": "true", "queryParam": {"ref": 1}, "cookie": "session=active", "host": 5
+	}}, {"type": "remove", "selector": ".ad", "condition": "mobile"}]`
+	want := []string{
+		"actions[0].condition.cookie",
+		"actions[0].condition.host",
+		"actions[0].condition.isLoggedIn",
+		"actions[0].condition.queryParam.ref",
+		"actions[0].condition.urll",
+		"actions[1].condition",
+	}
+	for name, unmarshal := range map[string]func([]byte, interface{}) error{"json": json.Unmarshal, "yaml": yaml.Unmarshal} {
+		var actions Actions
+		verrs, _ := asValidationErrors(unmarshal([]byte(bad), &actions))
+		if len(verrs) != len(want) {
+			t.Errorf("%s: errors = %v, want paths %v", name, verrs, want)
+			continue
+		}
+		for i, e := range verrs {
+			if e.Path != want[i] {
+				t.Errorf("%s: error %d path = %q, want %q", name, i, e.Path, want[i])
+			}
+		}
+		if verrs[4].Code != codeUnknownField {
+			t.Errorf("%s: unknown key code = %q", name, verrs[4].Code)
+		}
+	}
+}
+
+func TestActionsClone(t *testing.T) {
+	p := 5
+	orig := Actions{&RemoveAction{Type: "remove", Selector: ".a", ActionBase: ActionBase{Priority: &p, Condition: &Condition{URL: "/", Cookie: map[string]string{"a": "1"}}}}}
+	cp := orig.clone()
+	rm := cp[0].(*RemoveAction)
+	rm.Selector = ".b"
+	*rm.Priority = 1
+	rm.Condition.URL = "/x"
+	rm.Condition.Cookie["a"] = "2"
+	o := orig[0].(*RemoveAction)
+	if o.Selector != ".a" || *o.Priority != 5 || o.Condition.URL != "/" || o.Condition.Cookie["a"] != "1" {
+		t.Errorf("original mutated through clone: %+v", o)
+	}
+}
+
+func TestHandlersReturnStructuredValidationErrors(t *testing.T) {
+	useMemoryStore(t)
+	router := newRouter()
+	doRequest(router, "POST", "/api/pages", `{"id": "blog", "name": "Blog"}`, nil)
+
+	for _, tt := range []struct {
+		method, url, body string
+		want              []ValidationError
+	}{
+		{"POST", "/api/configuration", `{"id": "demo", "actions": [{"type": "remove", "selector": ".ad", "priority": "1"}]}`,
+			[]ValidationError{{Path: "actions[0].priority", Code: codeInvalidType}}},
+		{"POST", "/api/specific", `{"id": "demo", "actions": [{"type": "remove", "selecter": ".ad"}]}`,
+			[]ValidationError{{Path: "actions[0].selecter", Code: codeUnknownField}, {Path: "actions[0].selector", Code: codeRequired}}},
+		{"POST", "/api/pages", `{"id": "demo", "actions": [{"type": "explode"}]}`,
+			[]ValidationError{{Path: "actions[0].type", Code: codeUnknownActionType}}},
+		{"POST", "/api/configuration", `{"actions": []}`,
+			[]ValidationError{{Path: "id", Code: codeRequired}}},
+		{"POST", "/api/specific", `{"id": "demo"}`,
+			[]ValidationError{{Path: "actions", Code: codeRequired}}},
+		{"PUT", "/api/configuration/demo?upsert=true", `{"actions": {"type": "remove"}}`,
+			[]ValidationError{{Path: "actions", Code: codeInvalidType}}},
+		{"PUT", "/api/pages/blog", `{"actions": [{"type": "remove", "selector": ".a"}, {"type": "insert", "target": "body", "element": "<b>x</b>", "position": "inside"}]}`,
+			[]ValidationError{{Path: "actions[1].position", Code: codeInvalidEnum}}},
+	} {
+		w := doRequest(router, tt.method, tt.url, tt.body, nil)
+		var res struct {
+			Errors []ValidationError `json:"errors"`
+		}
+		if w.Code != http.StatusUnprocessableEntity || json.Unmarshal(w.Body.Bytes(), &res) != nil {
+			t.Errorf("%s %s: %d %s", tt.method, tt.url, w.Code, w.Body.String())
+			continue
+		}
+		if len(res.Errors) != len(tt.want) {
+			t.Errorf("%s %s: errors = %+v, want %+v", tt.method, tt.url, res.Errors, tt.want)
+			continue
+		}
+		for i, e := range res.Errors {
+			if e.Path != tt.want[i].Path || e.Code != tt.want[i].Code || e.Message == "" {
+				t.Errorf("%s %s: error %d = %+v, want %+v", tt.method, tt.url, i, e, tt.want[i])
+			}
+		}
+	}
+}
+
+// Kurallar sıkılaştıktan sonra da kayıtlı config okunur; sorunları raporlanır, yazarken reddedilir
+func TestStoredPagesConfigReadLeniently(t *testing.T) {
+	s := useMemoryStore(t)
+	legacy := "id: legacy\ndatasource:\n  urls:\n    /x: x.yaml\nactions:\n" +
+		"  - type: remove\n    selector: \"div >\"\n" +
+		"  - type: hide\n    selector: .a\n" +
+		"  - type: remove\n    selector: .ok\n"
+	if err := s.Put(KindPages, "legacy", []byte(legacy)); err != nil {
+		t.Fatal(err)
+	}
+	router := newRouter()
+
+	w := doRequest(router, "GET", "/api/pages/legacy", "", nil)
+	var cfg struct {
+		Actions  []interface{}    `json:"actions"`
+		Problems ValidationErrors `json:"validation_errors"`
+	}
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &cfg) != nil {
+		t.Fatalf("GET: %d %s", w.Code, w.Body.String())
+	}
+	if len(cfg.Actions) != 2 || len(cfg.Problems) != 2 ||
+		cfg.Problems[0].Path != "actions[0].selector" || cfg.Problems[0].Code != codeInvalidSelector ||
+		cfg.Problems[1].Path != "actions[1].type" || cfg.Problems[1].Code != codeUnknownActionType {
+		t.Errorf("GET: %s", w.Body.String())
+	}
+	if w := doRequest(router, "GET", "/api/pages/all", "", nil); !strings.Contains(w.Body.String(), `"id":"legacy"`) {
+		t.Errorf("all: %s", w.Body.String())
+	}
+
+	// Resolve config'i atlamaz, neden kullanılamadığını söyler
+	w = doRequest(router, "GET", "/api/pages/resolve?url=/x", "", nil)
+	if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), `"path":"actions[0].selector"`) {
+		t.Errorf("resolve: %d %s", w.Code, w.Body.String())
+	}
+
+	// Yazma yolu katıdır
+	w = doRequest(router, "PUT", "/api/pages/legacy", `{"actions": [{"type": "remove", "selector": "div >"}]}`, nil)
+	if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), codeInvalidSelector) {
+		t.Errorf("PUT: %d %s", w.Code, w.Body.String())
+	}
+
+	if w := doRequest(router, "GET", "/api/pages/yok", "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("missing: %d", w.Code)
+	}
+	if err := s.Put(KindPages, "broken", []byte("id: [")); err != nil {
+		t.Fatal(err)
+	}
+	if w := doRequest(router, "GET", "/api/pages/broken", "", nil); w.Code != http.StatusInternalServerError {
+		t.Errorf("unparsable: %d %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,474 @@
+package main
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+// alter newValue içinde kullanılabilen {{DEĞİŞKEN}}'ler; frontend (visionbridge.js templateVariables) ile aynı set
+var templateVariables = map[string]string{
+	"USER_NAME":  "localStorage.userName",
+	"HOST":       "location.hostname",
+	"PATH":       "location.pathname",
+	"URL":        "location.href",
+	"PAGE_TITLE": "document.title",
+	"DATE":       "yerel tarih",
+	"YEAR":       "yıl",
+}
+
+var templatePlaceholder = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// Bir regex oldValue'nun en fazla uzunluğu
+const maxAlterRegexLength = 256
+
+func (a *AlterAction) check() actionErrors {
+	var errs actionErrors
+	if requireField(&errs, "oldValue", a.OldValue) && a.Regex != nil && *a.Regex {
+		// frontend caseSensitive: false için RegExp'e "i" bayrağı ekler
+		foldCase := a.CaseSensitive != nil && !*a.CaseSensitive
+		if e := checkAlterRegex(a.OldValue, foldCase); e != nil {
+			errs = append(errs, e)
+		}
+	}
+	if requireField(&errs, "newValue", a.NewValue) {
+		errs = append(errs, checkTemplate("newValue", a.NewValue)...)
+	}
+	return errs
+}
+
+// {{...}} yer tutucularını denetle: söz dizimi bozuk olanlar ve bilinmeyen değişkenler hatadır
+func checkTemplate(field, value string) actionErrors {
+	var errs actionErrors
+	for _, m := range templatePlaceholder.FindAllStringSubmatch(value, -1) {
+		if _, ok := templateVariables[m[1]]; !ok {
+			errs = append(errs, &actionError{Field: field, Code: codeUnknownVariable,
+				Message: "bilinmeyen değişken " + m[0] + " (bilinenler: " + strings.Join(knownTemplateVariables(), ", ") + ")"})
+		}
+	}
+	rest := templatePlaceholder.ReplaceAllString(value, "")
+	if strings.Contains(rest, "{{") || strings.Contains(rest, "}}") {
+		errs = append(errs, &actionError{Field: field, Code: codeInvalidTemplate, Message: "yer tutucu {{DEĞİŞKEN}} biçiminde olmalı"})
+	}
+	return errs
+}
+
+func knownTemplateVariables() []string {
+	names := make([]string, 0, len(templateVariables))
+	for name := range templateVariables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// newValue düz metin olarak sayfaya yazılır; içindeki etiketler atılır, metin olduğu gibi kalır.
+// Etiketler atıldıktan sonra birleşen metin yeni bir etiket oluşturabileceği
+// (ör. "<<b>script>") için işaretleme kalmayana kadar tekrarlanır.
+func stripMarkup(input string) (string, []RemovedConstruct) {
+	var removed removals
+	for {
+		var out strings.Builder
+		changed := false
+		z := html.NewTokenizer(strings.NewReader(input))
+		skip, skipDepth := "", 0
+		for {
+			tt := z.Next()
+			if tt == html.ErrorToken {
+				break
+			}
+			raw := string(z.Raw())
+			if tt != html.TextToken {
+				changed = true
+			}
+			tok := z.Token()
+			if skip != "" {
+				switch {
+				case tt == html.StartTagToken && tok.Data == skip:
+					skipDepth++
+				case tt == html.EndTagToken && tok.Data == skip:
+					skipDepth--
+					if skipDepth == 0 {
+						skip = ""
+					}
+				}
+				continue
+			}
+			switch tt {
+			case html.TextToken:
+				out.WriteString(raw)
+			case html.StartTagToken, html.SelfClosingTagToken:
+				if forbiddenElements[tok.Data] || dropContentElements[tok.Data] {
+					removed.add(RemovedConstruct{Kind: "element", Element: tok.Data})
+					if tt == html.StartTagToken && !voidElements[tok.Data] {
+						skip, skipDepth = tok.Data, 1
+					}
+					continue
+				}
+				removed.add(RemovedConstruct{Kind: "tag", Element: tok.Data})
+			case html.EndTagToken:
+				removed.add(RemovedConstruct{Kind: "tag", Element: tok.Data})
+			case html.CommentToken:
+				removed.add(RemovedConstruct{Kind: "comment"})
+			case html.DoctypeToken:
+				removed.add(RemovedConstruct{Kind: "doctype"})
+			}
+		}
+		if !changed {
+			return input, removed
+		}
+		input = out.String()
+	}
+}
+
+// regex: true ile oldValue tarayıcıda `new RegExp(oldValue, "g")` olarak çalışır. Desen RE2
+// ile derlenir (geri referans ve lookaround gibi geri izleme gerektiren yapılar kabul
+// edilmez), RE2'ye özgü olup JavaScript'te hata veren ya da başka anlama gelen yapılar
+// reddedilir ve tarayıcının geri izlemeli motorunda üstel süre alabilecek yapılar reddedilir.
+func checkAlterRegex(pattern string, foldCase bool) *actionError {
+	if len(pattern) > maxAlterRegexLength {
+		return &actionError{Field: "oldValue", Code: codeInvalidRegex, Message: "regex en fazla 256 karakter olabilir"}
+	}
+	if _, err := syntax.Parse(pattern, syntax.Perl); err != nil {
+		return &actionError{Field: "oldValue", Code: codeInvalidRegex, Message: "geçersiz veya desteklenmeyen regex: " + err.Error()}
+	}
+	if construct := jsIncompatibleRegex(pattern); construct != "" {
+		return &actionError{Field: "oldValue", Code: codeInvalidRegex, Message: "JavaScript RegExp ile uyumsuz yapı: " + construct}
+	}
+	p := &regexScanner{src: pattern}
+	items := p.sequence()
+	if reason := backtrackRisk(items, foldCase); reason != "" {
+		return &actionError{Field: "oldValue", Code: codeUnsafeRegex, Message: "felaket geri izleme riski: " + reason}
+	}
+	return nil
+}
+
+// RE2'nin kabul ettiği ama tarayıcının RegExp'inin (u bayrağı olmadan) reddettiği ya da
+// farklı yorumladığı ilk yapı; yoksa "". Büyük/küçük harf duyarsızlığı yalnızca
+// caseSensitive: false ile verilir, satır içi bayraklar kabul edilmez.
+func jsIncompatibleRegex(pattern string) string {
+	inClass := false
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			switch e := pattern[i]; e {
+			// \A, \z: metin sınırları; \Q...\E: literal blok; \C: tek bayt;
+			// \pL, \p{Greek}: Unicode sınıfları (JS'te u bayrağı olmadan düz "p")
+			case 'A', 'z', 'Q', 'E', 'C', 'p', 'P':
+				return `\` + string(e)
+			case 'x':
+				if i+1 < len(pattern) && pattern[i+1] == '{' {
+					return `\x{...}`
+				}
+			}
+		case inClass:
+			if c == ']' {
+				inClass = false
+			} else if c == '[' && i+1 < len(pattern) && pattern[i+1] == ':' {
+				return "[[:sınıf:]] POSIX sınıfı"
+			}
+		case c == '[':
+			inClass = true
+			// RE2'de sınıfın başındaki ] düz karakterdir; JS'te [] boş sınıftır
+			rest := strings.TrimPrefix(pattern[i+1:], "^")
+			if strings.HasPrefix(rest, "]") {
+				return "sınıfın başında ]"
+			}
+		case c == '(' && strings.HasPrefix(pattern[i+1:], "?"):
+			rest := pattern[i+2:]
+			if strings.HasPrefix(rest, ":") || strings.HasPrefix(rest, "<") && !strings.HasPrefix(rest, "<=") && !strings.HasPrefix(rest, "<!") {
+				continue
+			}
+			if strings.HasPrefix(rest, "P<") {
+				return "(?P<ad>...) adlandırılmış grup; (?<ad>...) kullanın"
+			}
+			// RE2 ayrıştırması geçtiği için bayrakların ardından : ya da ) gelir
+			flags := rest[:strings.IndexAny(rest, ":)")]
+			return "satır içi bayrak (?" + flags + "); büyük/küçük harf için caseSensitive: false kullanın"
+		}
+	}
+	return ""
+}
+
+// Desenin kaynak düzeyindeki yapısı. RE2 ayrıştırıcısı "(\w|\d)+" veya "(?:a+)+" gibi
+// ifadeleri sadeleştirdiği için risk analizi tarayıcının göreceği ham desen üzerinde yapılır.
+type regexItem struct {
+	src       string        // öğenin kaynak metni (niceleyici hariç)
+	branches  [][]regexItem // grup ise alternatifleri
+	branchSrc []string
+	unbounded bool // *, + veya {n,}
+	repeated  bool // birden fazla tekrar edebilir: sınırsız ya da {n,m} ile m > 1
+}
+
+type regexScanner struct {
+	src string
+	pos int
+}
+
+// Alternatiflerden birini (| veya ) görene kadar) oku
+func (p *regexScanner) sequence() []regexItem {
+	var items []regexItem
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if c == '|' || c == ')' {
+			break
+		}
+		start := p.pos
+		var item regexItem
+		switch c {
+		case '\\':
+			p.pos += 2
+		case '[':
+			p.skipClass()
+		case '(':
+			p.pos++
+			p.skipGroupPrefix()
+			for {
+				bstart := p.pos
+				item.branches = append(item.branches, p.sequence())
+				item.branchSrc = append(item.branchSrc, p.src[bstart:min(p.pos, len(p.src))])
+				if p.pos >= len(p.src) || p.src[p.pos] != '|' {
+					break
+				}
+				p.pos++
+			}
+			p.pos++ // ')'
+		default:
+			p.pos++
+		}
+		item.src = p.src[start:min(p.pos, len(p.src))]
+		item.unbounded, item.repeated = p.quantifier()
+		items = append(items, item)
+	}
+	return items
+}
+
+func (p *regexScanner) skipClass() {
+	p.pos++
+	if p.pos < len(p.src) && p.src[p.pos] == '^' {
+		p.pos++
+	}
+	if p.pos < len(p.src) && p.src[p.pos] == ']' {
+		p.pos++
+	}
+	for p.pos < len(p.src) && p.src[p.pos] != ']' {
+		if p.src[p.pos] == '\\' {
+			p.pos++
+		}
+		p.pos++
+	}
+	p.pos++
+}
+
+// (?: ve (?<ad> öneklerini atla
+func (p *regexScanner) skipGroupPrefix() {
+	if p.pos >= len(p.src) || p.src[p.pos] != '?' {
+		return
+	}
+	end := strings.IndexAny(p.src[p.pos:], ":>)")
+	if end < 0 {
+		return
+	}
+	if p.src[p.pos+end] != ')' {
+		end++
+	}
+	p.pos += end
+}
+
+var regexBraceQuantifier = regexp.MustCompile(`^\{(\d+)(,(\d*))?\}`)
+
+// Niceleyiciyi oku; sınırsız tekrar ve birden fazla tekrar durumunu döndürür
+func (p *regexScanner) quantifier() (unbounded, repeated bool) {
+	if p.pos >= len(p.src) {
+		return false, false
+	}
+	switch c := p.src[p.pos]; {
+	case c == '*' || c == '+':
+		unbounded, repeated = true, true
+		p.pos++
+	case c == '?':
+		p.pos++
+	case c == '{':
+		m := regexBraceQuantifier.FindStringSubmatch(p.src[p.pos:])
+		if m == nil {
+			return false, false
+		}
+		max := m[1]
+		if m[2] != "" {
+			max = m[3]
+		}
+		n, _ := strconv.Atoi(max)
+		unbounded = max == ""
+		repeated = unbounded || n > 1
+		p.pos += len(m[0])
+	default:
+		return false, false
+	}
+	// tembel / iyelik son eki
+	if p.pos < len(p.src) && (p.src[p.pos] == '?' || p.src[p.pos] == '+') {
+		p.pos++
+	}
+	return unbounded, repeated
+}
+
+// Birden fazla kez tekrarlanan bir grup için üç yapı üstel geri izlemeye yol açar:
+// içinde sınırsız bir tekrar, boş eşleşebilmesi ve aynı karakterle başlayabilen alternatifler.
+// Sınırlı tekrar da ({20}) yolları çarpar: (.*a){20} sınırsız tekrar kadar yavaştır.
+func backtrackRisk(items []regexItem, foldCase bool) string {
+	for _, it := range items {
+		if it.branches == nil {
+			continue
+		}
+		if it.repeated {
+			if containsUnbounded(it.branches) {
+				return it.src + " tekrarlanan grup içinde sınırsız niceleyici"
+			}
+			if re, err := syntax.Parse(it.src, syntax.Perl); err == nil && minMatchLen(re) == 0 {
+				return it.src + " boş eşleşebilen bir grubu tekrarlıyor"
+			}
+			if a, b, ok := overlappingBranches(it.branchSrc, foldCase); ok {
+				return it.src + " içinde '" + a + "' ve '" + b + "' aynı karakterle başlayabiliyor"
+			}
+		}
+		for _, branch := range it.branches {
+			if reason := backtrackRisk(branch, foldCase); reason != "" {
+				return reason
+			}
+		}
+	}
+	return ""
+}
+
+func containsUnbounded(branches [][]regexItem) bool {
+	for _, branch := range branches {
+		for _, it := range branch {
+			if it.unbounded || containsUnbounded(it.branches) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func overlappingBranches(branches []string, foldCase bool) (string, string, bool) {
+	flags := syntax.Perl
+	if foldCase {
+		flags |= syntax.FoldCase
+	}
+	sets := make([]runeSet, len(branches))
+	for i, b := range branches {
+		re, err := syntax.Parse(b, flags)
+		if err != nil {
+			continue
+		}
+		sets[i] = firstRunes(re)
+	}
+	for i := range sets {
+		for j := i + 1; j < len(sets); j++ {
+			if sets[i].overlaps(sets[j]) {
+				return branches[i], branches[j], true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// Bir ifadenin eşleşebileceği en kısa metin uzunluğu
+func minMatchLen(re *syntax.Regexp) int {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return len(re.Rune)
+	case syntax.OpCharClass, syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return 1
+	case syntax.OpCapture, syntax.OpPlus:
+		return minMatchLen(re.Sub[0])
+	case syntax.OpRepeat:
+		return re.Min * minMatchLen(re.Sub[0])
+	case syntax.OpConcat:
+		n := 0
+		for _, sub := range re.Sub {
+			n += minMatchLen(sub)
+		}
+		return n
+	case syntax.OpAlternate:
+		n := -1
+		for _, sub := range re.Sub {
+			if m := minMatchLen(sub); n < 0 || m < n {
+				n = m
+			}
+		}
+		return max(n, 0)
+	}
+	return 0
+}
+
+// Rune aralıkları kümesi; any tüm karakterleri kapsar
+type runeSet struct {
+	ranges []rune // [lo, hi] çiftleri
+	any    bool
+}
+
+func (s runeSet) overlaps(o runeSet) bool {
+	if (s.any && (o.any || len(o.ranges) > 0)) || (o.any && len(s.ranges) > 0) {
+		return true
+	}
+	for i := 0; i+1 < len(s.ranges); i += 2 {
+		for j := 0; j+1 < len(o.ranges); j += 2 {
+			if s.ranges[i] <= o.ranges[j+1] && o.ranges[j] <= s.ranges[i+1] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *runeSet) union(o runeSet) {
+	s.any = s.any || o.any
+	s.ranges = append(s.ranges, o.ranges...)
+}
+
+// İfadenin eşleşmesinin ilk karakteri olabilecek rune'lar
+func firstRunes(re *syntax.Regexp) runeSet {
+	switch re.Op {
+	case syntax.OpLiteral:
+		if len(re.Rune) == 0 {
+			return runeSet{}
+		}
+		r := re.Rune[0]
+		set := runeSet{ranges: []rune{r, r}}
+		if re.Flags&syntax.FoldCase != 0 {
+			for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+				set.ranges = append(set.ranges, f, f)
+			}
+		}
+		return set
+	case syntax.OpCharClass:
+		return runeSet{ranges: re.Rune}
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return runeSet{any: true}
+	case syntax.OpCapture, syntax.OpPlus, syntax.OpStar, syntax.OpQuest, syntax.OpRepeat:
+		return firstRunes(re.Sub[0])
+	case syntax.OpAlternate:
+		var set runeSet
+		for _, sub := range re.Sub {
+			set.union(firstRunes(sub))
+		}
+		return set
+	case syntax.OpConcat:
+		var set runeSet
+		for _, sub := range re.Sub {
+			set.union(firstRunes(sub))
+			if minMatchLen(sub) > 0 {
+				break
+			}
+		}
+		return set
+	}
+	return runeSet{}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckAlterRegex(t *testing.T) {
+	safe := []string{
+		`\d+`, `^Fiyat: \d+(\.\d{2})? TL$`, `(foo|bar)+`, `[a-z]+@[a-z]+\.com`,
+		`merhaba`, `(?:ab|cd){2,}`, `colou?r`, `(?<yil>\d{4})-(\d{2})`, `[^\]]+`, `a{2,5}`,
+		`(\d{1,3}\.){3}\d{1,3}`, `(ab|cd){3}`, `(a+)?b`, `(x+){1}`,
+	}
+	for _, p := range safe {
+		if e := checkAlterRegex(p, false); e != nil {
+			t.Errorf("%q should be accepted: %s %s", p, e.Code, e.Message)
+		}
+	}
+
+	tests := []struct {
+		pattern string
+		code    string
+	}{
+		{`(a+)+$`, codeUnsafeRegex},
+		{`(?:a+)+b`, codeUnsafeRegex},
+		{`([a-z]*)*`, codeUnsafeRegex},
+		{`(\d+\s?)+$`, codeUnsafeRegex},
+		{`(a|a)*b`, codeUnsafeRegex},
+		{`(\w|\d)+$`, codeUnsafeRegex},
+		{`(a|ab)+c`, codeUnsafeRegex},
+		{`(.|x){2,}`, codeUnsafeRegex},
+		{`(a?)+`, codeUnsafeRegex},
+		{`(A|a)+`, ""},
+		{`((ab)*c)*`, codeUnsafeRegex},
+		{`(.*a){20}`, codeUnsafeRegex},
+		{`(\w+\s){2,5}$`, codeUnsafeRegex},
+		{`(a|a){10}b`, codeUnsafeRegex},
+		{`(a?){25}a{25}`, codeUnsafeRegex},
+		// RE2 kabul eder, tarayıcının RegExp'i reddeder ya da başka anlamda okur
+		{`(?i)merhaba`, codeInvalidRegex},
+		{`(?i:(A|a)+)`, codeInvalidRegex},
+		{`(?s).`, codeInvalidRegex},
+		{`(?P<n>a)`, codeInvalidRegex},
+		{`\Aa\z`, codeInvalidRegex},
+		{`\Qa.b\E`, codeInvalidRegex},
+		{`[[:alpha:]]+`, codeInvalidRegex},
+		{`\pL+`, codeInvalidRegex},
+		{`[\p{Greek}]`, codeInvalidRegex},
+		{`\x{41}`, codeInvalidRegex},
+		{`[]a]`, codeInvalidRegex},
+		{`(a`, codeInvalidRegex},
+		{`a)`, codeInvalidRegex},
+		{`(?=a)b`, codeInvalidRegex},
+		{`(a)\1`, codeInvalidRegex},
+		{strings.Repeat("a", 257), codeInvalidRegex},
+	}
+	for _, tt := range tests {
+		e := checkAlterRegex(tt.pattern, false)
+		if tt.code == "" {
+			// Büyük/küçük harf duyarsızken aynı alternatifler çakışır
+			if e != nil || checkAlterRegex(tt.pattern, true) == nil {
+				t.Errorf("%q: fold case handling wrong: %+v", tt.pattern, e)
+			}
+			continue
+		}
+		if e == nil || e.Code != tt.code || e.Field != "oldValue" {
+			t.Errorf("%q: got %+v, want %s", tt.pattern, e, tt.code)
+		}
+	}
+}
+
+func TestCheckTemplate(t *testing.T) {
+	for _, v := range []string{"Merhaba {{USER_NAME}}!", "{{ YEAR }} - {{HOST}}", "düz metin", "{tek} süslü"} {
+		if errs := checkTemplate("newValue", v); len(errs) != 0 {
+			t.Errorf("%q: unexpected errors %v", v, errs.validationErrors())
+		}
+	}
+	tests := map[string][]string{
+		"Merhaba {{USERNAME}}":         {codeUnknownVariable},
+		"{{user_name}} ve {{FOO}}":     {codeUnknownVariable, codeUnknownVariable},
+		"Merhaba {{USER_NAME}":         {codeInvalidTemplate},
+		"{{ USER NAME }}":              {codeInvalidTemplate},
+		"{{}}":                         {codeInvalidTemplate},
+		"{{FOO}} ve kapanmamış {{YEAR": {codeUnknownVariable, codeInvalidTemplate},
+	}
+	for v, codes := range tests {
+		errs := checkTemplate("newValue", v)
+		if len(errs) != len(codes) {
+			t.Errorf("%q: got %v, want %v", v, errs.validationErrors(), codes)
+			continue
+		}
+		for i, code := range codes {
+			if errs[i].Code != code {
+				t.Errorf("%q[%d]: got %s, want %s", v, i, errs[i].Code, code)
+			}
+		}
+	}
+}
+
+func TestStripMarkup(t *testing.T) {
+	tests := []struct {
+		in, want string
+		removed  int
+	}{
+		{"Merhaba dünya", "Merhaba dünya", 0},
+		{"a < b & c > d", "a < b & c > d", 0},
+		{"Tom &amp; Jerry", "Tom &amp; Jerry", 0},
+		{"<b>kalın</b> metin", "kalın metin", 1},
+		{`<img src=x onerror=alert(1)>fiyat`, "fiyat", 1},
+		{"x<script>alert(1)</script>y", "xy", 1},
+		{"<<b>script>alert(1)<</b>/script>", "", 2},
+		{"a<!-- yorum -->b", "ab", 1},
+		{"</p>kapanış", "kapanış", 1},
+	}
+	for _, tt := range tests {
+		got, removed := stripMarkup(tt.in)
+		if got != tt.want || len(removed) != tt.removed {
+			t.Errorf("stripMarkup(%q) = %q, %+v; want %q with %d removals", tt.in, got, removed, tt.want, tt.removed)
+		}
+	}
+}
+
+func TestAlterActionValidation(t *testing.T) {
+	regex := true
+	actions := Actions{
+		&AlterAction{Type: "alter", OldValue: `(a+)+`, NewValue: "x", Regex: &regex},
+		&AlterAction{Type: "alter", OldValue: `(a+)+`, NewValue: "{{NOPE}}"},
+		&AlterAction{Type: "alter", OldValue: `\d+ TL`, NewValue: "<b>{{USER_NAME}}</b> fiyat", Regex: &regex},
+	}
+	_, err := validateAndSanitizeActions(actions, defaultSanitizer, false)
+	verrs, _ := asValidationErrors(err)
+	if len(verrs) != 2 || verrs[0].Path != "actions[0].oldValue" || verrs[0].Code != codeUnsafeRegex ||
+		verrs[1].Path != "actions[1].newValue" || verrs[1].Code != codeUnknownVariable {
+		t.Fatalf("errors = %+v", verrs)
+	}
+
+	actions = Actions{&AlterAction{Type: "alter", OldValue: `\d+ TL`, NewValue: "<b>{{USER_NAME}}</b> fiyat", Regex: &regex}}
+	report, err := validateAndSanitizeActions(actions, defaultSanitizer, false)
+	if err != nil {
+		t.Fatalf("valid alter rejected: %v", err)
+	}
+	if got := actions[0].(*AlterAction).NewValue; got != "{{USER_NAME}} fiyat" {
+		t.Errorf("newValue = %q", got)
+	}
+	if len(report) != 1 || report[0].Field != "newValue" {
+		t.Errorf("report = %+v", report)
+	}
+
+	strict := Actions{&AlterAction{Type: "alter", OldValue: "a", NewValue: "<i>b</i>"}}
+	if _, err := validateAndSanitizeActions(strict, defaultSanitizer, true); err == nil {
+		t.Error("strict mode should reject markup in newValue")
+	}
+
+	decoded, err := decodeActions([]interface{}{map[string]interface{}{"type": "alter", "oldValue": "a", "newValue": "b", "regex": "yes"}})
+	if verrs, _ := asValidationErrors(err); len(verrs) != 1 || verrs[0].Path != "actions[0].regex" {
+		t.Errorf("regex type check: %v %v", decoded, err)
+	}
+}
@@ -0,0 +1,356 @@
+package main
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// ChangeDetector, içeriği değiştiğinde farklı bir parmak izi üreten store'lar için.
+// CachedStore bunu periyodik olarak sorgulayarak dışarıdan yapılan değişiklikleri yakalar.
+type ChangeDetector interface {
+	Fingerprint() (string, error)
+}
+
+// Parse edilmiş konfigürasyonu doğrudan sunabilen store'lar (bkz. CachedStore)
+type parsedConfigSource interface {
+	ParsedConfig(kind ConfigKind, id string) (Config, error)
+	ParsedPages(id string) (*PagesConfig, error)
+}
+
+type cacheEntry struct {
+	data     []byte
+	cfg      Config
+	cfgErr   error
+	pages    *PagesConfig
+	pagesErr error
+}
+
+func newCacheEntry(kind ConfigKind, data []byte) *cacheEntry {
+	e := &cacheEntry{data: data}
+	e.cfg, e.cfgErr = parseConfig(data)
+	if kind == KindPages {
+		e.pages, e.pagesErr = parsePagesConfig(data)
+	}
+	return e
+}
+
+// CachedStore: alttaki store'un tüm içeriğini parse edilmiş halde bellekte tutar.
+// Okumalar diske gitmez; kendi yazmaları anında, dış değişiklikler Watch ile yansır.
+// Transaction desteği alttaki store'a bağlıdır; global store olarak Store() kullanılmalı.
+type CachedStore struct {
+	base ConfigStore
+
+	// writeMu yazmaları ve yeniden yüklemeyi sıraya koyar, mu indeksi korur
+	writeMu     sync.Mutex
+	mu          sync.RWMutex
+	entries     map[ConfigKind]map[string]*cacheEntry
+	fingerprint string
+
+	stop chan struct{}
+	once sync.Once
+}
+
+func NewCachedStore(base ConfigStore) (*CachedStore, error) {
+	c := &CachedStore{base: base, stop: make(chan struct{})}
+	if err := c.Reload(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Cache'in ConfigStore olarak kullanılacak hali: alttaki store TxStore ise transaction'lar
+// ona devredilir, değilse (ör. FileStore) withTx yazmaları tek tek uygular
+func (c *CachedStore) Store() ConfigStore {
+	if _, ok := c.base.(TxStore); ok {
+		return &txCachedStore{c}
+	}
+	return c
+}
+
+type txCachedStore struct {
+	*CachedStore
+}
+
+func (t *txCachedStore) Update(fn func(tx ConfigStore) error) error {
+	return t.update(fn)
+}
+
+// Reload, tüm indeksi alttaki store'dan yeniden oluşturur
+func (c *CachedStore) Reload() error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.reloadLocked()
+}
+
+func (c *CachedStore) reloadLocked() error {
+	var fp string
+	if cd, ok := c.base.(ChangeDetector); ok {
+		var err error
+		if fp, err = cd.Fingerprint(); err != nil {
+			return err
+		}
+	}
+	entries := make(map[ConfigKind]map[string]*cacheEntry)
+	for _, kind := range configKinds {
+		ids, err := c.base.List(kind)
+		if err != nil {
+			return err
+		}
+		entries[kind] = make(map[string]*cacheEntry, len(ids))
+		for _, id := range ids {
+			b, err := c.base.Get(kind, id)
+			if err != nil {
+				continue
+			}
+			entries[kind][id] = newCacheEntry(kind, b)
+		}
+	}
+	c.mu.Lock()
+	c.entries = entries
+	c.fingerprint = fp
+	c.mu.Unlock()
+	return nil
+}
+
+// Kendi yazmalarından sonra parmak izini güncelle; Watch bunları dış değişiklik sanıp
+// tüm indeksi yeniden yüklemesin. writeMu tutulurken çağrılır.
+func (c *CachedStore) refreshFingerprintLocked() {
+	cd, ok := c.base.(ChangeDetector)
+	if !ok {
+		return
+	}
+	if fp, err := cd.Fingerprint(); err == nil {
+		c.mu.Lock()
+		c.fingerprint = fp
+		c.mu.Unlock()
+	}
+}
+
+// Watch, alttaki store değiştiğinde indeksi yeniler. Store ChangeDetector değilse bir şey yapmaz.
+func (c *CachedStore) Watch(interval time.Duration) {
+	cd, ok := c.base.(ChangeDetector)
+	if !ok || interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.stop:
+				return
+			case <-ticker.C:
+				fp, err := cd.Fingerprint()
+				if err != nil {
+					continue
+				}
+				c.mu.RLock()
+				changed := fp != c.fingerprint
+				c.mu.RUnlock()
+				if changed {
+					c.Reload()
+				}
+			}
+		}
+	}()
+}
+
+// Close, Watch goroutine'ini durdurur
+func (c *CachedStore) Close() {
+	c.once.Do(func() { close(c.stop) })
+}
+
+func (c *CachedStore) entry(kind ConfigKind, id string) (*cacheEntry, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	e, ok := c.entries[kind][id]
+	if !ok {
+		return nil, ErrConfigNotFound
+	}
+	return e, nil
+}
+
+func (c *CachedStore) set(kind ConfigKind, id string, e *cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries[kind] == nil {
+		c.entries[kind] = make(map[string]*cacheEntry)
+	}
+	if e == nil {
+		delete(c.entries[kind], id)
+		return
+	}
+	c.entries[kind][id] = e
+}
+
+func (c *CachedStore) Get(kind ConfigKind, id string) ([]byte, error) {
+	e, err := c.entry(kind, id)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), e.data...), nil
+}
+
+func (c *CachedStore) List(kind ConfigKind) ([]string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	ids := make([]string, 0, len(c.entries[kind]))
+	for id := range c.entries[kind] {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func (c *CachedStore) Put(kind ConfigKind, id string, data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if err := c.base.Put(kind, id, data); err != nil {
+		return err
+	}
+	c.set(kind, id, newCacheEntry(kind, append([]byte(nil), data...)))
+	c.refreshFingerprintLocked()
+	return nil
+}
+
+func (c *CachedStore) Delete(kind ConfigKind, id string) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if err := c.base.Delete(kind, id); err != nil {
+		return err
+	}
+	c.set(kind, id, nil)
+	c.refreshFingerprintLocked()
+	return nil
+}
+
+// Alttaki store'un transaction desteğini kullanır ve transaction içinde dokunulan
+// kayıtları sonrasında tazeler (bkz. txCachedStore)
+func (c *CachedStore) update(fn func(tx ConfigStore) error) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	rec := &touchRecorder{}
+	err := withTx(c.base, func(tx ConfigStore) error {
+		rec.ConfigStore = tx
+		return fn(rec)
+	})
+	for _, k := range rec.touched {
+		b, gerr := c.base.Get(k.kind, k.id)
+		if gerr != nil {
+			c.set(k.kind, k.id, nil)
+			continue
+		}
+		c.set(k.kind, k.id, newCacheEntry(k.kind, b))
+	}
+	if len(rec.touched) > 0 {
+		c.refreshFingerprintLocked()
+	}
+	return err
+}
+
+// Revizyonlar ve çöp kutusu sıcak yolda değil; doğrudan alttaki store'a gider
+func (c *CachedStore) AddRevision(kind ConfigKind, id string, rev Revision) (Revision, error) {
+	return c.base.AddRevision(kind, id, rev)
+}
+
+func (c *CachedStore) Revisions(kind ConfigKind, id string) ([]Revision, error) {
+	return c.base.Revisions(kind, id)
+}
+
+func (c *CachedStore) GetRevision(kind ConfigKind, id string, number int) (*Revision, error) {
+	return c.base.GetRevision(kind, id, number)
+}
+
+func (c *CachedStore) PutTrash(entry TrashEntry) error {
+	return c.base.PutTrash(entry)
+}
+
+func (c *CachedStore) GetTrash(kind ConfigKind, id string) (*TrashEntry, error) {
+	return c.base.GetTrash(kind, id)
+}
+
+func (c *CachedStore) ListTrash() ([]TrashEntry, error) {
+	return c.base.ListTrash()
+}
+
+func (c *CachedStore) DeleteTrash(kind ConfigKind, id string) error {
+	return c.base.DeleteTrash(kind, id)
+}
+
+func (c *CachedStore) ParsedConfig(kind ConfigKind, id string) (Config, error) {
+	e, err := c.entry(kind, id)
+	if err != nil {
+		return nil, err
+	}
+	if e.cfgErr != nil {
+		return nil, e.cfgErr
+	}
+	return cloneValue(e.cfg).(Config), nil
+}
+
+func (c *CachedStore) ParsedPages(id string) (*PagesConfig, error) {
+	e, err := c.entry(KindPages, id)
+	if err != nil {
+		return nil, err
+	}
+	if e.pagesErr != nil {
+		return nil, e.pagesErr
+	}
+	return e.pages.clone(), nil
+}
+
+type cacheKey struct {
+	kind ConfigKind
+	id   string
+}
+
+// Transaction içinde yazılan kayıtları not eden sarmalayıcı
+type touchRecorder struct {
+	ConfigStore
+	touched []cacheKey
+}
+
+func (r *touchRecorder) Put(kind ConfigKind, id string, data []byte) error {
+	r.touched = append(r.touched, cacheKey{kind, id})
+	return r.ConfigStore.Put(kind, id, data)
+}
+
+func (r *touchRecorder) Delete(kind ConfigKind, id string) error {
+	r.touched = append(r.touched, cacheKey{kind, id})
+	return r.ConfigStore.Delete(kind, id)
+}
+
+// YAML'den gelen map/slice değerlerinin derin kopyası; cache'teki veriyi çağırandan korur
+func cloneValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case Config:
+		if t == nil {
+			return Config(nil)
+		}
+		out := make(Config, len(t))
+		for k, val := range t {
+			out[k] = cloneValue(val)
+		}
+		return out
+	case map[string]interface{}:
+		if t == nil {
+			return t
+		}
+		out := make(map[string]interface{}, len(t))
+		for k, val := range t {
+			out[k] = cloneValue(val)
+		}
+		return out
+	case []interface{}:
+		if t == nil {
+			return t
+		}
+		out := make([]interface{}, len(t))
+		for i, val := range t {
+			out[i] = cloneValue(val)
+		}
+		return out
+	}
+	return v
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCachedStoreContract(t *testing.T) {
+	c, err := NewCachedStore(NewFileStore(t.TempDir()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testConfigStoreContract(t, c)
+}
+
+func TestCachedStoreServesFromMemory(t *testing.T) {
+	dir := t.TempDir()
+	ioutil.WriteFile(filepath.Join(dir, "demo.yaml"), []byte("id: demo\nactions: []\n"), 0644)
+	c, err := NewCachedStore(NewFileStore(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Dosya silinse bile Watch çalışmadığı sürece cache'ten okunmalı
+	os.Remove(filepath.Join(dir, "demo.yaml"))
+	cfg, err := c.ParsedConfig(KindGeneral, "demo")
+	if err != nil || cfg["id"] != "demo" {
+		t.Fatalf("ParsedConfig = %v, %v", cfg, err)
+	}
+
+	// Dönen kopyayı değiştirmek cache'i etkilememeli
+	cfg["id"] = "changed"
+	cfg2, _ := c.ParsedConfig(KindGeneral, "demo")
+	if cfg2["id"] != "demo" {
+		t.Errorf("cache entry was mutated through returned copy: %v", cfg2["id"])
+	}
+}
+
+func TestCachedStoreWatchPicksUpExternalChanges(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewCachedStore(NewFileStore(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Watch(10 * time.Millisecond)
+	defer c.Close()
+
+	ioutil.WriteFile(filepath.Join(dir, "pages_blog.yaml"), []byte("id: blog\nname: Blog\n"), 0644)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if p, err := c.ParsedPages("blog"); err == nil {
+			if p.Name != "Blog" {
+				t.Errorf("ParsedPages name = %q", p.Name)
+			}
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("external file was not picked up by Watch")
+}
+
+func TestCachedStoreUpdateRefreshesTouchedEntries(t *testing.T) {
+	base, err := OpenSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer base.Close()
+	c, err := NewCachedStore(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, ok := c.Store().(TxStore)
+	if !ok {
+		t.Fatal("cache over SQLiteStore should support transactions")
+	}
+	err = tx.Update(func(tx ConfigStore) error {
+		return tx.Put(KindSpecific, "s1", []byte("id: s1\n"))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg, err := c.ParsedConfig(KindSpecific, "s1"); err != nil || cfg["id"] != "s1" {
+		t.Errorf("ParsedConfig after Update = %v, %v", cfg, err)
+	}
+}
+
+// FileStore transaction desteklemez; cache de desteklermiş gibi görünmemeli
+func TestCachedStoreTxFollowsBase(t *testing.T) {
+	c, err := NewCachedStore(NewFileStore(t.TempDir()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Store().(TxStore); ok {
+		t.Error("cache over FileStore advertises TxStore")
+	}
+}
+
+// Cache'in kendi yazmaları parmak izini günceller; Watch bunlar için indeksi yeniden yüklemez
+func TestCachedStoreOwnWritesUpdateFingerprint(t *testing.T) {
+	base := NewFileStore(t.TempDir())
+	c, err := NewCachedStore(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check := func(op string) {
+		t.Helper()
+		fp, err := base.Fingerprint()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.fingerprint != fp {
+			t.Errorf("%s: cached fingerprint %q, base %q", op, c.fingerprint, fp)
+		}
+	}
+	if err := c.Put(KindGeneral, "demo", []byte("id: demo\n")); err != nil {
+		t.Fatal(err)
+	}
+	check("Put")
+	if err := withTx(c.Store(), func(tx ConfigStore) error { return tx.Delete(KindGeneral, "demo") }); err != nil {
+		t.Fatal(err)
+	}
+	check("Delete")
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"sync"
@@ -15,6 +16,7 @@ import (
 
 // 100 Eşzamanlı Kullanıcı Testi
 func TestConcurrentUsers100(t *testing.T) {
+	useTempConfigDir(t, "demo.yaml")
 	// Test server'ı oluştur
 	router := mux.NewRouter()
 	router.HandleFunc("/api/configuration/all", handleGetAllConfigs).Methods("GET")
@@ -283,6 +285,7 @@ func TestStressTest200Users(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Stress test atlanıyor (short mode)")
 	}
+	useTempConfigDir(t, "demo.yaml")
 
 	// Test server'ı oluştur
 	router := mux.NewRouter()
@@ -374,4 +377,79 @@ func TestStressTest200Users(t *testing.T) {
 	} else {
 		fmt.Println("⚠️  Stress test sınırları zorladı")
 	}
-} 
\ No newline at end of file
+} 
+// Aynı id'ye eşzamanlı yazma testi: yarım yazılmış YAML hiçbir okuyucuya görünmemeli
+func TestConcurrentWritesSameID(t *testing.T) {
+	dir := useTempConfigDir(t)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/configuration/{id}", handleGetConfig).Methods("GET")
+	router.HandleFunc("/api/configuration", handlePostConfig).Methods("POST")
+	router.HandleFunc("/api/pages/{id}", handleGetPagesConfig).Methods("GET")
+	router.HandleFunc("/api/pages", handlePostPagesConfig).Methods("POST")
+
+	writers := 100
+	var wg sync.WaitGroup
+	errs := make(chan string, writers*4)
+
+	for i := 0; i < writers; i++ {
+		wg.Add(2)
+		go func(n int) {
+			defer wg.Done()
+			// Her yazıcı farklı uzunlukta içerik gönderir, böylece kesilmiş dosya parse edilemez
+			var actions []interface{}
+			for j := 0; j <= n%10; j++ {
+				actions = append(actions, map[string]interface{}{
+					"type":     "remove",
+					"selector": fmt.Sprintf(".writer-%d-%d", n, j),
+				})
+			}
+			for _, req := range []struct {
+				url  string
+				body interface{}
+			}{
+				{"/api/configuration", map[string]interface{}{"id": "shared", "actions": actions}},
+				{"/api/pages", map[string]interface{}{"id": "shared", "name": fmt.Sprintf("writer-%d", n), "actions": actions}},
+			} {
+				b, _ := json.Marshal(req.body)
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, httptest.NewRequest("POST", req.url, bytes.NewBuffer(b)))
+				if w.Code != http.StatusCreated {
+					errs <- fmt.Sprintf("POST %s: status %d: %s", req.url, w.Code, w.Body.String())
+				}
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for _, url := range []string{"/api/configuration/shared", "/api/pages/shared", "/api/configuration/shared", "/api/pages/shared"} {
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, httptest.NewRequest("GET", url, nil))
+				// Henüz yazılmadıysa 404 olabilir, ama asla parse hatası olmamalı
+				if w.Code != http.StatusOK && w.Code != http.StatusNotFound {
+					errs <- fmt.Sprintf("GET %s: status %d: %s", url, w.Code, w.Body.String())
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for e := range errs {
+		t.Error(e)
+	}
+
+	if _, err := loadConfig(KindGeneral, "shared"); err != nil {
+		t.Errorf("final general config is not readable: %v", err)
+	}
+	if _, err := loadPagesConfig("shared"); err != nil {
+		t.Errorf("final pages config is not readable: %v", err)
+	}
+	files, _ := ioutil.ReadDir(dir)
+	for _, f := range files {
+		if f.IsDir() {
+			continue // revizyon geçmişi
+		}
+		if f.Name() != "shared.yaml" && f.Name() != "pages_shared.yaml" {
+			t.Errorf("leftover file after concurrent writes: %s", f.Name())
+		}
+	}
+}
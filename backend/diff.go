@@ -0,0 +1,284 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// Bir alanın eski ve yeni değeri
+type ValueChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// Anahtar-değer haritaları arasındaki fark (datasource, metadata)
+type MapDiff struct {
+	Added   map[string]interface{} `json:"added,omitempty"`
+	Removed map[string]interface{} `json:"removed,omitempty"`
+	Changed map[string]ValueChange `json:"changed,omitempty"`
+}
+
+func (d MapDiff) empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Eklenen/silinen/değişen anahtarları tek bir from/to listesinde topla
+func (d MapDiff) changes() map[string]ValueChange {
+	out := map[string]ValueChange{}
+	for k, c := range d.Changed {
+		out[k] = c
+	}
+	for k, v := range d.Added {
+		out[k] = ValueChange{To: v}
+	}
+	for k, v := range d.Removed {
+		out[k] = ValueChange{From: v}
+	}
+	return out
+}
+
+// Aynı anahtara sahip (type + selector/target/oldValue) ama içeriği değişmiş action
+type ActionChange struct {
+	Key     map[string]interface{} `json:"key"`
+	Changes map[string]ValueChange `json:"changes"`
+}
+
+type ActionsDiff struct {
+	Added    []interface{}  `json:"added,omitempty"`
+	Removed  []interface{}  `json:"removed,omitempty"`
+	Modified []ActionChange `json:"modified,omitempty"`
+}
+
+type ConfigDiff struct {
+	Actions    ActionsDiff        `json:"actions"`
+	Datasource map[string]MapDiff `json:"datasource,omitempty"`
+	Metadata   MapDiff            `json:"metadata"`
+	// Diğer üst seviye alanlar (name, extends vb.)
+	Fields map[string]ValueChange `json:"fields,omitempty"`
+}
+
+// YAML/JSON kaynaklı değerleri karşılaştırılabilir hale getir (Config -> map, int -> float64)
+func normalizeValue(v interface{}) interface{} {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out interface{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return v
+	}
+	return out
+}
+
+func asMap(v interface{}) map[string]interface{} {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m
+	case Config:
+		// yaml.v3, Config'e çözerken iç içe map'leri de Config olarak üretir
+		return m
+	}
+	return nil
+}
+
+func diffMaps(from, to map[string]interface{}) MapDiff {
+	d := MapDiff{}
+	for k, v := range from {
+		nv, ok := to[k]
+		if !ok {
+			if d.Removed == nil {
+				d.Removed = map[string]interface{}{}
+			}
+			d.Removed[k] = v
+		} else if !reflect.DeepEqual(v, nv) {
+			if d.Changed == nil {
+				d.Changed = map[string]ValueChange{}
+			}
+			d.Changed[k] = ValueChange{From: v, To: nv}
+		}
+	}
+	for k, v := range to {
+		if _, ok := from[k]; !ok {
+			if d.Added == nil {
+				d.Added = map[string]interface{}{}
+			}
+			d.Added[k] = v
+		}
+	}
+	return d
+}
+
+// Action eşleştirme anahtarı: type + hedeflediği şey
+func actionKey(act map[string]interface{}) map[string]interface{} {
+	key := map[string]interface{}{"type": act["type"]}
+	for _, field := range []string{"selector", "target", "oldValue"} {
+		if v, ok := act[field]; ok {
+			key[field] = v
+			break
+		}
+	}
+	return key
+}
+
+func diffActions(from, to []interface{}) ActionsDiff {
+	// Aynı anahtar birden çok kez geçebilir; sırayla eşleştirilir
+	type slot struct {
+		act  map[string]interface{}
+		used bool
+	}
+	var slots []*slot
+	byKey := map[string][]*slot{}
+	for _, a := range from {
+		s := &slot{act: asMap(a)}
+		k := fmt.Sprint(actionKey(s.act))
+		slots = append(slots, s)
+		byKey[k] = append(byKey[k], s)
+	}
+
+	d := ActionsDiff{}
+	for _, a := range to {
+		act := asMap(a)
+		var match *slot
+		for _, s := range byKey[fmt.Sprint(actionKey(act))] {
+			if !s.used {
+				match = s
+				break
+			}
+		}
+		if match == nil {
+			d.Added = append(d.Added, act)
+			continue
+		}
+		match.used = true
+		fields := diffMaps(match.act, act)
+		if fields.empty() {
+			continue
+		}
+		d.Modified = append(d.Modified, ActionChange{Key: actionKey(act), Changes: fields.changes()})
+	}
+	for _, s := range slots {
+		if !s.used {
+			d.Removed = append(d.Removed, s.act)
+		}
+	}
+	return d
+}
+
+// İki konfigürasyon (genel, spesifik veya pages) arasındaki yapısal fark
+func diffConfigs(from, to Config) ConfigDiff {
+	f := asMap(normalizeValue(from))
+	t := asMap(normalizeValue(to))
+
+	fromActions, _ := f["actions"].([]interface{})
+	toActions, _ := t["actions"].([]interface{})
+	d := ConfigDiff{
+		Actions:  diffActions(fromActions, toActions),
+		Metadata: diffMaps(asMap(f["metadata"]), asMap(t["metadata"])),
+	}
+
+	fromDS, toDS := asMap(f["datasource"]), asMap(t["datasource"])
+	for _, section := range []string{"pages", "urls", "hosts"} {
+		if md := diffMaps(asMap(fromDS[section]), asMap(toDS[section])); !md.empty() {
+			if d.Datasource == nil {
+				d.Datasource = map[string]MapDiff{}
+			}
+			d.Datasource[section] = md
+		}
+	}
+
+	rest := func(m map[string]interface{}) map[string]interface{} {
+		out := map[string]interface{}{}
+		for k, v := range m {
+			switch k {
+			case "actions", "datasource", "metadata":
+			default:
+				out[k] = v
+			}
+		}
+		return out
+	}
+	if fields := diffMaps(rest(f), rest(t)); !fields.empty() {
+		d.Fields = fields.changes()
+	}
+	return d
+}
+
+// Revizyon içeriğini parse et; silme revizyonları boş config sayılır
+func revisionConfig(kind ConfigKind, id string, number int) (Config, error) {
+	rev, err := store.GetRevision(kind, id, number)
+	if err != nil {
+		return nil, err
+	}
+	if len(rev.Data) == 0 {
+		return Config{}, nil
+	}
+	return parseConfig(rev.Data)
+}
+
+// GET /api/{configuration|specific|pages}/{id}/diff?from=1&to=3
+// to verilmezse güncel kayıtlı config ile karşılaştırılır
+func handleDiffRevisions(kind ConfigKind) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := mux.Vars(r)["id"]
+		q := r.URL.Query()
+		from, err := strconv.Atoi(q.Get("from"))
+		if err != nil || from < 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": "from parametresi geçerli bir revizyon numarası olmalı"}`))
+			return
+		}
+		fromCfg, err := revisionConfig(kind, id, from)
+		if err == ErrRevisionNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": "Revizyon bulunamadı"}`))
+			return
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error": "Revizyon okunamadı"}`))
+			return
+		}
+
+		var toCfg Config
+		var toLabel interface{} = "current"
+		if q.Get("to") != "" {
+			to, convErr := strconv.Atoi(q.Get("to"))
+			if convErr != nil || to < 1 {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error": "to parametresi geçerli bir revizyon numarası olmalı"}`))
+				return
+			}
+			toLabel = to
+			toCfg, err = revisionConfig(kind, id, to)
+			if err == ErrRevisionNotFound {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"error": "Revizyon bulunamadı"}`))
+				return
+			}
+		} else {
+			toCfg, err = loadConfig(kind, id)
+			if err == ErrConfigNotFound {
+				toCfg, err = Config{}, nil
+			}
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error": "Config okunamadı"}`))
+			return
+		}
+
+		result := map[string]interface{}{
+			"id":   id,
+			"from": from,
+			"to":   toLabel,
+			"diff": diffConfigs(fromCfg, toCfg),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	}
+}
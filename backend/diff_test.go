@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestDiffConfigs(t *testing.T) {
+	from, _ := parseConfig([]byte(`
+id: blog
+name: Blog
+datasource:
+  pages:
+    home: blog_home.yaml
+    post: blog_post.yaml
+  hosts:
+    blog.example.com: blog_main.yaml
+actions:
+  - type: remove
+    selector: ".sidebar-ads"
+    priority: 10
+  - type: alter
+    oldValue: "Machine Learning"
+    newValue: "AI & ML"
+  - type: replace
+    selector: ".old-footer"
+    newElement: "<footer>2024</footer>"
+metadata:
+  version: "1.0"
+  author: alice
+`))
+	to, _ := parseConfig([]byte(`
+id: blog
+name: Blog Site
+datasource:
+  pages:
+    home: blog_home_v2.yaml
+    archive: blog_archive.yaml
+  hosts:
+    blog.example.com: blog_main.yaml
+actions:
+  - type: remove
+    selector: ".sidebar-ads"
+    priority: 5
+  - type: alter
+    oldValue: "Machine Learning"
+    newValue: "AI & ML"
+  - type: insert
+    target: ".post-content"
+    position: prepend
+    element: "<div>5 dk</div>"
+metadata:
+  version: "1.1"
+  reviewed: true
+`))
+	d := diffConfigs(from, to)
+
+	if len(d.Actions.Added) != 1 || asMap(d.Actions.Added[0])["type"] != "insert" {
+		t.Errorf("added actions = %v", d.Actions.Added)
+	}
+	if len(d.Actions.Removed) != 1 || asMap(d.Actions.Removed[0])["selector"] != ".old-footer" {
+		t.Errorf("removed actions = %v", d.Actions.Removed)
+	}
+	if len(d.Actions.Modified) != 1 {
+		t.Fatalf("modified actions = %v", d.Actions.Modified)
+	}
+	mod := d.Actions.Modified[0]
+	if mod.Key["selector"] != ".sidebar-ads" || mod.Changes["priority"] != (ValueChange{From: 10.0, To: 5.0}) {
+		t.Errorf("modified action = %+v", mod)
+	}
+
+	pages := d.Datasource["pages"]
+	if pages.Added["archive"] != "blog_archive.yaml" || pages.Removed["post"] != "blog_post.yaml" ||
+		pages.Changed["home"] != (ValueChange{From: "blog_home.yaml", To: "blog_home_v2.yaml"}) {
+		t.Errorf("datasource.pages diff = %+v", pages)
+	}
+	if _, ok := d.Datasource["hosts"]; ok {
+		t.Errorf("unchanged hosts must not appear in diff: %+v", d.Datasource["hosts"])
+	}
+
+	if d.Metadata.Changed["version"] != (ValueChange{From: "1.0", To: "1.1"}) ||
+		d.Metadata.Added["reviewed"] != true || d.Metadata.Removed["author"] != "alice" {
+		t.Errorf("metadata diff = %+v", d.Metadata)
+	}
+	if d.Fields["name"] != (ValueChange{From: "Blog", To: "Blog Site"}) {
+		t.Errorf("fields diff = %+v", d.Fields)
+	}
+}
+
+func TestDiffEndpoint(t *testing.T) {
+	useMemoryStore(t)
+	router := newRouter()
+	doRequest(router, "POST", "/api/pages", `{"id": "shop", "name": "Shop", "actions": [{"type": "remove", "selector": ".ad"}]}`, nil)
+	doRequest(router, "PUT", "/api/pages/shop", `{"name": "Shop", "actions": [{"type": "remove", "selector": ".ad"}, {"type": "remove", "selector": ".popup"}]}`, nil)
+
+	w := doRequest(router, "GET", "/api/pages/shop/diff?from=1&to=2", "", nil)
+	var res struct {
+		Diff ConfigDiff `json:"diff"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("diff: %d %s", w.Code, w.Body.String())
+	}
+	if len(res.Diff.Actions.Added) != 1 || len(res.Diff.Actions.Removed) != 0 || len(res.Diff.Actions.Modified) != 0 {
+		t.Errorf("diff actions = %+v", res.Diff.Actions)
+	}
+
+	if w := doRequest(router, "GET", "/api/pages/shop/diff?from=1", "", nil); w.Code != http.StatusOK {
+		t.Errorf("diff against current: %d %s", w.Code, w.Body.String())
+	}
+	if w := doRequest(router, "GET", "/api/pages/shop/diff?from=1&to=9", "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("diff with missing revision: want 404, got %d", w.Code)
+	}
+	if w := doRequest(router, "GET", "/api/pages/shop/diff", "", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("diff without from: want 400, got %d", w.Code)
+	}
+}
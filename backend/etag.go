@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Yanıt gövdesinden türetilen strong ETag
+func configETag(data []byte) string {
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// If-Match / If-None-Match başlığındaki etag listesi verilen etag'i içeriyor mu.
+// If-Match güçlü karşılaştırma ister (RFC 7232 §3.1): W/ önekli etag'ler yalnız weak ise eşleşir.
+func etagListMatches(header, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// Koşullu yazmalarda kontrol ve yazma arasına başka bir yazma girmesin diye (kind, id) başına kilit
+var configLocks sync.Map
+
+func lockConfig(kind ConfigKind, id string) func() {
+	m, _ := configLocks.LoadOrStore(string(kind)+"/"+id, &sync.Mutex{})
+	mu := m.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
+// PUT/DELETE için If-Match kontrolü. Koşul sağlanmazsa 412 yazar ve false döner.
+// Çağıran lockConfig ile aynı id'yi kilitlemiş olmalı.
+func checkIfMatch(w http.ResponseWriter, r *http.Request, kind ConfigKind, id string) bool {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		return true
+	}
+	b, err := renderConfig(kind, id)
+	if err == nil && etagListMatches(header, configETag(b), false) {
+		return true
+	}
+	if err == nil {
+		w.Header().Set("ETag", configETag(b))
+	}
+	w.WriteHeader(http.StatusPreconditionFailed)
+	w.Write([]byte(`{"error": "Config bu arada değişmiş (If-Match eşleşmedi)"}`))
+	return false
+}
+
+// GET /api/{kind}/{id} yanıtının gövdesi: saklanan içerik ve okurken hesaplanan alanlar
+// (applied_sanitizer_policy, validation_errors, yeniden temizlenmiş HTML). ETag'ler
+// bu baytlardan hesaplanır; politika değişince içerik değişmese de ETag değişir.
+func renderConfig(kind ConfigKind, id string) ([]byte, error) {
+	switch kind {
+	case KindPages:
+		cfg, err := loadPagesConfig(id)
+		if err != nil {
+			return nil, err
+		}
+		return renderValue(cfg)
+	case KindPolicy:
+		p, err := loadSanitizerPolicy(id)
+		if err != nil {
+			return nil, err
+		}
+		return renderValue(p)
+	}
+	cfg, err := loadConfig(kind, id)
+	if err != nil {
+		return nil, err
+	}
+	return renderValue(cfg)
+}
+
+// Saklanan baytların (ör. bir revizyonun) GET yanıtı olarak gövdesi
+func renderConfigData(kind ConfigKind, data []byte) ([]byte, error) {
+	switch kind {
+	case KindPages:
+		cfg, err := parsePagesConfig(data)
+		if err != nil {
+			return nil, err
+		}
+		return renderValue(cfg)
+	case KindPolicy:
+		p, err := parseSanitizerPolicy(data)
+		if err != nil {
+			return nil, err
+		}
+		return renderValue(p)
+	}
+	cfg, err := parseConfig(data)
+	if err != nil {
+		return nil, err
+	}
+	return renderValue(cfg)
+}
+
+func renderValue(v interface{}) ([]byte, error) {
+	switch c := v.(type) {
+	case *PagesConfig:
+		ps, _ := loadPolicySet()
+		v = ps.annotatePages(c)
+	case Config:
+		v = withAppliedPolicy(c)
+	}
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GET yanıtını ETag'iyle yazar; If-None-Match eşleşirse gövdesiz 304 döner
+func writeRendered(w http.ResponseWriter, r *http.Request, body []byte) {
+	etag := configETag(body)
+	w.Header().Set("ETag", etag)
+	if header := r.Header.Get("If-None-Match"); header != "" && etagListMatches(header, etag, true) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+// Yazmadan sonra yeni sürümün ETag'ini yanıta ekle
+func setStoredETag(w http.ResponseWriter, kind ConfigKind, id string) {
+	if b, err := renderConfig(kind, id); err == nil {
+		w.Header().Set("ETag", configETag(b))
+	}
+}
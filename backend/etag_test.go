@@ -0,0 +1,136 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func doRequest(h http.Handler, method, url, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, url, strings.NewReader(body))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestConfigETagPreconditions(t *testing.T) {
+	useMemoryStore(t)
+	router := newRouter()
+
+	body := `{"id": "demo", "actions": [{"type": "remove", "selector": ".ad"}]}`
+	w := doRequest(router, "POST", "/api/configuration", body, nil)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("POST: %d %s", w.Code, w.Body.String())
+	}
+
+	w = doRequest(router, "GET", "/api/configuration/demo", "", nil)
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag == "" {
+		t.Fatalf("GET: status %d, etag %q", w.Code, etag)
+	}
+
+	w = doRequest(router, "GET", "/api/configuration/demo", "", map[string]string{"If-None-Match": etag})
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("GET If-None-Match: want 304 with empty body, got %d %q", w.Code, w.Body.String())
+	}
+
+	update := `{"id": "demo", "actions": [{"type": "remove", "selector": ".banner"}]}`
+	w = doRequest(router, "PUT", "/api/configuration/demo", update, map[string]string{"If-Match": `"stale"`})
+	if w.Code != http.StatusPreconditionFailed {
+		t.Errorf("PUT stale If-Match: want 412, got %d", w.Code)
+	}
+
+	w = doRequest(router, "PUT", "/api/configuration/demo", update, map[string]string{"If-Match": etag})
+	newETag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || newETag == "" || newETag == etag {
+		t.Fatalf("PUT matching If-Match: status %d, etag %q (old %q)", w.Code, newETag, etag)
+	}
+
+	// Eski ETag ile yapılan ikinci düzenleme artık reddedilmeli
+	w = doRequest(router, "PUT", "/api/configuration/demo", body, map[string]string{"If-Match": etag})
+	if w.Code != http.StatusPreconditionFailed {
+		t.Errorf("PUT with outdated ETag: want 412, got %d", w.Code)
+	}
+	w = doRequest(router, "DELETE", "/api/configuration/demo", "", map[string]string{"If-Match": etag})
+	if w.Code != http.StatusPreconditionFailed {
+		t.Errorf("DELETE with outdated ETag: want 412, got %d", w.Code)
+	}
+	w = doRequest(router, "DELETE", "/api/configuration/demo", "", map[string]string{"If-Match": newETag})
+	if w.Code != http.StatusOK {
+		t.Errorf("DELETE with current ETag: want 200, got %d", w.Code)
+	}
+}
+
+func TestPagesETagPreconditions(t *testing.T) {
+	useMemoryStore(t)
+	router := newRouter()
+
+	w := doRequest(router, "POST", "/api/pages", `{"id": "blog", "name": "Blog"}`, nil)
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusCreated || etag == "" {
+		t.Fatalf("POST: status %d, etag %q", w.Code, etag)
+	}
+	w = doRequest(router, "GET", "/api/pages/blog", "", nil)
+	if got := w.Header().Get("ETag"); got != etag {
+		t.Errorf("GET etag %q differs from POST etag %q", got, etag)
+	}
+	// Weak etag If-Match'i hiçbir zaman sağlamaz
+	w = doRequest(router, "PUT", "/api/pages/blog", `{"name": "Blog v2"}`, map[string]string{"If-Match": `W/` + etag})
+	if w.Code != http.StatusPreconditionFailed {
+		t.Errorf("PUT weak If-Match: want 412, got %d", w.Code)
+	}
+	w = doRequest(router, "GET", "/api/pages/blog", "", map[string]string{"If-None-Match": `W/` + etag})
+	if w.Code != http.StatusNotModified {
+		t.Errorf("GET weak If-None-Match: want 304, got %d", w.Code)
+	}
+	w = doRequest(router, "PUT", "/api/pages/blog", `{"name": "Blog v2"}`, map[string]string{"If-Match": etag})
+	if w.Code != http.StatusOK {
+		t.Fatalf("PUT If-Match: %d %s", w.Code, w.Body.String())
+	}
+	w = doRequest(router, "PUT", "/api/pages/blog", `{"name": "Blog v3"}`, map[string]string{"If-Match": etag})
+	if w.Code != http.StatusPreconditionFailed {
+		t.Errorf("PUT outdated If-Match: want 412, got %d", w.Code)
+	}
+}
+
+// ETag sunulan gövdeden hesaplanır: hesaplanan alanlar değişince (ör. politika) ETag da değişir
+func TestETagCoversComputedFields(t *testing.T) {
+	useMemoryStore(t)
+	router := newRouter()
+
+	w := doRequest(router, "POST", "/api/configuration", `{"id": "shop", "datasource": {"hosts": {"shop.example.com": "x"}},
+		"actions": [{"type": "replace", "selector": ".x", "newElement": "<div class=\"box\"><b>x</b></div>"}]}`, nil)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("POST: %d %s", w.Code, w.Body.String())
+	}
+	w = doRequest(router, "GET", "/api/configuration/shop", "", nil)
+	etag := w.Header().Get("ETag")
+	if etag != configETag(w.Body.Bytes()) {
+		t.Errorf("GET etag %q is not derived from the body", etag)
+	}
+
+	// Saklanan içerik aynı kalsa da hosta bağlanan politika yanıtı değiştirir
+	if w := doRequest(router, "POST", "/api/policies", `{"id": "text", "hosts": ["shop.example.com"], "elements": {"b": []}}`, nil); w.Code != http.StatusCreated {
+		t.Fatalf("POST policy: %d %s", w.Code, w.Body.String())
+	}
+	w = doRequest(router, "GET", "/api/configuration/shop", "", map[string]string{"If-None-Match": etag})
+	newETag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"applied_sanitizer_policy":"text"`) || newETag == etag || newETag != configETag(w.Body.Bytes()) {
+		t.Fatalf("GET after policy change: %d %q %s", w.Code, newETag, w.Body.String())
+	}
+	if w := doRequest(router, "GET", "/api/configuration/shop", "", map[string]string{"If-None-Match": newETag}); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("GET If-None-Match: %d %s", w.Code, w.Body.String())
+	}
+
+	// If-Match da aynı gövdeyle karşılaştırır
+	if w := doRequest(router, "DELETE", "/api/configuration/shop", "", map[string]string{"If-Match": etag}); w.Code != http.StatusPreconditionFailed {
+		t.Errorf("DELETE stale If-Match: %d %s", w.Code, w.Body.String())
+	}
+	if w := doRequest(router, "DELETE", "/api/configuration/shop", "", map[string]string{"If-Match": newETag}); w.Code != http.StatusOK {
+		t.Errorf("DELETE If-Match: %d %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"gopkg.in/yaml.v3"
+)
+
+// Config kalıtımı: `extends: <id>` aynı türdeki başka bir config'in aksiyonlarını ve
+// datasource'unu devralır. Birleştirme kökten çocuğa doğru yapılır:
+//
+//	datasource  pages/urls/hosts eşlemeleri anahtar bazında birleşir, çocuğunki kazanır
+//	actions     çocuğun aksiyonu aynı anahtarlı (bkz. actionKey) üst aksiyonun yerine geçer,
+//	            yenileri sona eklenir; omit_actions'taki anahtarlar üstten gelmez
+//	diğerleri   çocukta varsa çocuğunki
+//	kimlik      id, sanitizer_policy ve applied_sanitizer_policy devralınmaz; politika
+//	            çocuğun kendi seçimiyle (yoksa birleştirilmiş hostlarıyla) çözülür
+//
+// Saklanan config değişmez; birleştirilmiş hal okurken (resolve, spesifik arama,
+// /flattened) hesaplanır. Birleştirilmiş aksiyonlar çocuğa uygulanan politikayla yeniden
+// temizlenir: üstten gelen HTML üstün (belki daha serbest) politikasıyla saklanmıştır.
+
+// ActionKey: devralınan bir aksiyonu tanımlayan anahtar (type + selector/target/oldValue)
+type ActionKey struct {
+	Type     string `yaml:"type" json:"type"`
+	Selector string `yaml:"selector,omitempty" json:"selector,omitempty"`
+	Target   string `yaml:"target,omitempty" json:"target,omitempty"`
+	OldValue string `yaml:"oldValue,omitempty" json:"oldValue,omitempty"`
+}
+
+// cfg ve ataları, cfg başta olacak şekilde. Eksik üst config ve döngü doğrulama hatasıdır.
+func extendsChain(kind ConfigKind, cfg Config) ([]Config, error) {
+	id, _ := cfg["id"].(string)
+	parent, _ := cfg["extends"].(string)
+	chain := []Config{cfg}
+	ids := []string{id}
+	for parent != "" {
+		if containsString(ids, parent) {
+			msg := "döngüsel extends: " + strings.Join(append(ids, parent), " → ")
+			return nil, ValidationErrors{{Path: "extends", Code: codeExtendsCycle, Message: msg}}
+		}
+		if err := validateConfigID(kind, parent); err != nil {
+			return nil, ValidationErrors{{Path: "extends", Code: codeInvalidFormat, Message: parent + ": " + err.Error()}}
+		}
+		p, err := loadConfig(kind, parent)
+		if err == ErrConfigNotFound {
+			return nil, ValidationErrors{{Path: "extends", Code: codeMissingRef, Message: "extends edilen config bulunamadı: " + parent}}
+		}
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, p)
+		ids = append(ids, parent)
+		parent, _ = p["extends"].(string)
+	}
+	return chain, nil
+}
+
+// id'yi doğrudan extends eden aynı türdeki configlerin id'leri
+func extendingConfigs(s ConfigStore, kind ConfigKind, id string) ([]string, error) {
+	ids, err := s.List(kind)
+	if err != nil {
+		return nil, err
+	}
+	var children []string
+	for _, cid := range ids {
+		b, err := s.Get(kind, cid)
+		if err != nil {
+			continue
+		}
+		// Okunamayan configler extends bilgisi de okunamadığından silmeyi engellemez
+		var head struct {
+			Extends string `yaml:"extends"`
+		}
+		if yaml.Unmarshal(b, &head) == nil && head.Extends == id && cid != id {
+			children = append(children, cid)
+		}
+	}
+	return children, nil
+}
+
+// Config'in extends zinciriyle birleştirilmiş hali; extends ve omit_actions alanları düşer
+func flattenConfig(kind ConfigKind, cfg Config) (Config, error) {
+	if cfg["extends"] == nil && cfg["omit_actions"] == nil {
+		return cfg, nil
+	}
+	chain, err := extendsChain(kind, cfg)
+	if err != nil {
+		return nil, err
+	}
+	flat := flattenChain(chain)
+	if err := sanitizeFlattenedActions(flat); err != nil {
+		return nil, err
+	}
+	return flat, nil
+}
+
+// Birleştirilmiş aksiyonları config'e uygulanan politikayla (bkz. withAppliedPolicy) temizle
+func sanitizeFlattenedActions(cfg Config) error {
+	if cfg["actions"] == nil {
+		return nil
+	}
+	explicit, _ := cfg["sanitizer_policy"].(string)
+	sanitizer, err := currentSanitizer(explicit, configHosts(cfg))
+	if err != nil {
+		return err
+	}
+	actions, err := decodeActions(cfg["actions"])
+	if err == nil {
+		_, err = validateAndSanitizeActions(actions, sanitizer, false)
+	}
+	if err != nil {
+		return err
+	}
+	cfg["actions"] = normalizeValue(actions)
+	return nil
+}
+
+// Zinciri kökten (sondaki) çocuğa (baştaki) doğru birleştir
+func flattenChain(chain []Config) Config {
+	out := asMap(normalizeValue(chain[len(chain)-1]))
+	for i := len(chain) - 2; i >= 0; i-- {
+		out = mergeConfig(out, asMap(normalizeValue(chain[i])))
+	}
+	delete(out, "extends")
+	delete(out, "omit_actions")
+	return Config(out)
+}
+
+// Üstten çocuğa geçmeyen, config'in kendisine ait alanlar
+var nonInheritedKeys = []string{"id", "sanitizer_policy", "applied_sanitizer_policy"}
+
+func mergeConfig(parent, child map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(parent)+len(child))
+	for k, v := range parent {
+		if !containsString(nonInheritedKeys, k) {
+			out[k] = v
+		}
+	}
+	for k, v := range child {
+		switch k {
+		case "datasource":
+			out[k] = mergeDatasource(asMap(parent[k]), asMap(v))
+		case "actions":
+		default:
+			out[k] = v
+		}
+	}
+	parentActions, _ := parent["actions"].([]interface{})
+	childActions, _ := child["actions"].([]interface{})
+	omit, _ := child["omit_actions"].([]interface{})
+	out["actions"] = mergeActions(parentActions, childActions, omit)
+	return out
+}
+
+func mergeDatasource(parent, child map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(parent)+len(child))
+	for k, v := range parent {
+		out[k] = v
+	}
+	for k, v := range child {
+		pm, cm := asMap(out[k]), asMap(v)
+		if pm == nil || cm == nil {
+			out[k] = v
+			continue
+		}
+		merged := make(map[string]interface{}, len(pm)+len(cm))
+		for mk, mv := range pm {
+			merged[mk] = mv
+		}
+		for mk, mv := range cm {
+			merged[mk] = mv
+		}
+		out[k] = merged
+	}
+	return out
+}
+
+func mergeActions(parent, child, omit []interface{}) []interface{} {
+	omitted := map[string]bool{}
+	for _, o := range omit {
+		omitted[fmt.Sprint(actionKey(asMap(o)))] = true
+	}
+	out := []interface{}{}
+	// Aynı anahtar birden çok kez geçebilir; çocuğun aksiyonları sırayla eşleşir (diffActions gibi)
+	slots := map[string][]int{}
+	for _, a := range parent {
+		k := fmt.Sprint(actionKey(asMap(a)))
+		if omitted[k] {
+			continue
+		}
+		slots[k] = append(slots[k], len(out))
+		out = append(out, a)
+	}
+	for _, a := range child {
+		k := fmt.Sprint(actionKey(asMap(a)))
+		if s := slots[k]; len(s) > 0 {
+			out[s[0]] = a
+			slots[k] = s[1:]
+			continue
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+// Kaydedilecek config'in extends zincirini ve omit_actions anahtarlarını doğrula;
+// omit_actions'taki her anahtar devralınan bir aksiyona karşılık gelmeli.
+func validateExtends(kind ConfigKind, cfg Config) error {
+	chain, err := extendsChain(kind, cfg)
+	if err != nil {
+		return err
+	}
+	omit, _ := normalizeValue(cfg["omit_actions"]).([]interface{})
+	inherited := map[string]bool{}
+	if len(chain) > 1 {
+		actions, _ := flattenChain(chain[1:])["actions"].([]interface{})
+		for _, a := range actions {
+			inherited[fmt.Sprint(actionKey(asMap(a)))] = true
+		}
+	}
+	var errs ValidationErrors
+	for i, o := range omit {
+		if !inherited[fmt.Sprint(actionKey(asMap(o)))] {
+			errs = append(errs, ValidationError{Path: fmt.Sprintf("omit_actions[%d]", i), Code: codeUnknownAction, Message: "devralınan aksiyonlarda bu anahtar yok"})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Genel/spesifik gövde için validateExtends; sorun varsa yanıtı yazar ve false döner
+func validateBodyExtends(w http.ResponseWriter, kind ConfigKind, cfg Config) bool {
+	if err := validateExtends(kind, cfg); err != nil {
+		if !writeValidationError(w, err) {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error": "Üst config okunamadı"}`))
+		}
+		return false
+	}
+	return true
+}
+
+// Pages config'in birleştirilmiş hali
+func flattenPagesConfig(cfg *PagesConfig) (*PagesConfig, error) {
+	if cfg.Extends == "" && len(cfg.OmitActions) == 0 {
+		return cfg, nil
+	}
+	flat, err := flattenConfig(KindPages, Config(asMap(normalizeValue(cfg))))
+	if err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(flat)
+	if err != nil {
+		return nil, err
+	}
+	var out PagesConfig
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GET /api/{configuration|specific|pages}/{id}/flattened
+func handleGetFlattened(kind ConfigKind) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := mux.Vars(r)["id"]
+		cfg, err := loadConfig(kind, id)
+		if err == ErrConfigNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": "Config bulunamadı"}`))
+			return
+		}
+		if err == nil {
+			cfg, err = flattenConfig(kind, cfg)
+		}
+		if err != nil {
+			if writeValidationError(w, err) {
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error": "Config okunamadı"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(withAppliedPolicy(cfg))
+	}
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// Aksiyonların kısa gösterimi: "type:selector/target/oldValue" (değiştirilen alanla birlikte)
+func describeActions(actions []interface{}) string {
+	var out []string
+	for _, a := range actions {
+		act := asMap(a)
+		key := actionKey(act)
+		s := fmt.Sprint(act["type"])
+		for _, field := range []string{"selector", "target", "oldValue"} {
+			if v, ok := key[field]; ok {
+				s += ":" + fmt.Sprint(v)
+			}
+		}
+		for _, field := range []string{"newElement", "element", "newValue"} {
+			if v, ok := act[field]; ok {
+				s += "=" + fmt.Sprint(v)
+			}
+		}
+		out = append(out, s)
+	}
+	return strings.Join(out, ",")
+}
+
+func TestExtendsFlattensGeneralConfigs(t *testing.T) {
+	useMemoryStore(t)
+	router := newRouter()
+
+	for _, body := range []string{
+		`{"id": "base", "name": "Temel", "actions": [
+			{"type": "remove", "selector": ".ad"},
+			{"type": "replace", "selector": ".old-footer", "newElement": "<footer>Eski</footer>"},
+			{"type": "alter", "oldValue": "ML", "newValue": "Yapay Zeka"}]}`,
+		`{"id": "blog", "extends": "base", "omit_actions": [{"type": "alter", "oldValue": "ML"}], "actions": [
+			{"type": "replace", "selector": ".old-footer", "newElement": "<footer>Blog</footer>"},
+			{"type": "remove", "selector": ".popup"}]}`,
+		`{"id": "blog-tr", "name": "Blog TR", "extends": "blog", "actions": [{"type": "remove", "selector": ".ad", "priority": 5}]}`,
+	} {
+		if w := doRequest(router, "POST", "/api/configuration", body, nil); w.Code != http.StatusCreated {
+			t.Fatalf("POST: %d %s", w.Code, w.Body.String())
+		}
+	}
+
+	flattened := func(id string) map[string]interface{} {
+		t.Helper()
+		w := doRequest(router, "GET", "/api/configuration/"+id+"/flattened", "", nil)
+		var cfg map[string]interface{}
+		if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &cfg) != nil {
+			t.Fatalf("%s: %d %s", id, w.Code, w.Body.String())
+		}
+		return cfg
+	}
+
+	// Aynı anahtarlı aksiyon yerinde değişir, omit_actions'taki gelmez, yeniler sona eklenir
+	cfg := flattened("blog")
+	actions, _ := cfg["actions"].([]interface{})
+	if got := describeActions(actions); got != "remove:.ad,replace:.old-footer=<footer>Blog</footer>,remove:.popup" {
+		t.Errorf("blog actions = %s", got)
+	}
+	if cfg["id"] != "blog" || cfg["name"] != "Temel" || cfg["extends"] != nil || cfg["omit_actions"] != nil {
+		t.Errorf("blog = %v", cfg)
+	}
+
+	cfg = flattened("blog-tr")
+	actions, _ = cfg["actions"].([]interface{})
+	if got := describeActions(actions); got != "remove:.ad,replace:.old-footer=<footer>Blog</footer>,remove:.popup" {
+		t.Errorf("blog-tr actions = %s", got)
+	}
+	if asMap(actions[0])["priority"] != float64(5) || cfg["name"] != "Blog TR" {
+		t.Errorf("blog-tr = %v", cfg)
+	}
+
+	// Saklanan config değişmez
+	w := doRequest(router, "GET", "/api/configuration/blog", "", nil)
+	if !strings.Contains(w.Body.String(), `"extends":"base"`) {
+		t.Errorf("stored config changed: %s", w.Body.String())
+	}
+	if w := doRequest(router, "GET", "/api/configuration/yok/flattened", "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("missing config: %d", w.Code)
+	}
+
+	tests := []struct {
+		method, path, body, errPath, code, message string
+	}{
+		{"PUT", "/api/configuration/base", `{"extends": "blog-tr", "actions": []}`, "extends", codeExtendsCycle, "base → blog-tr → blog → base"},
+		{"PUT", "/api/configuration/base", `{"extends": "base", "actions": []}`, "extends", codeExtendsCycle, "base → base"},
+		{"POST", "/api/configuration", `{"id": "x", "extends": "yok", "actions": []}`, "extends", codeMissingRef, "yok"},
+		{"POST", "/api/configuration", `{"id": "x", "extends": "../specific/a", "actions": []}`, "extends", codeInvalidFormat, ""},
+		{"POST", "/api/configuration", `{"id": "x", "extends": "base", "omit_actions": [{"type": "remove", "selector": ".yok"}], "actions": []}`, "omit_actions[0]", codeUnknownAction, ""},
+		{"POST", "/api/configuration", `{"id": "x", "omit_actions": [{"type": "remove", "selector": ".ad"}], "actions": []}`, "omit_actions[0]", codeUnknownAction, ""},
+		{"POST", "/api/configuration", `{"id": "x", "extends": "base", "omit_actions": [{"selector": ".ad"}], "actions": []}`, "omit_actions[0].type", codeRequired, ""},
+	}
+	for _, tt := range tests {
+		w := doRequest(router, tt.method, tt.path, tt.body, nil)
+		var body struct {
+			Errors ValidationErrors `json:"errors"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &body)
+		if w.Code != http.StatusUnprocessableEntity || len(body.Errors) != 1 || body.Errors[0].Path != tt.errPath ||
+			body.Errors[0].Code != tt.code || !strings.Contains(body.Errors[0].Message, tt.message) {
+			t.Errorf("%s %s: %d %s", tt.method, tt.body, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestExtendsInheritsDatasource(t *testing.T) {
+	s := useMemoryStore(t)
+	putRefConfigs(t, s, "post.yaml", "shop.yaml")
+	router := newRouter()
+
+	// Spesifik: devralınan hostlarla eşleşir, yanıt birleştirilmiş haldir
+	for _, body := range []string{
+		`{"id": "shop", "datasource": {"hosts": {"shop.example.com": "x"}}, "actions": [{"type": "remove", "selector": ".ad"}]}`,
+		`{"id": "shop-mobile", "extends": "shop", "datasource": {"hosts": {"m.example.com": "x"}}, "actions": [{"type": "remove", "selector": ".desktop"}]}`,
+	} {
+		if w := doRequest(router, "POST", "/api/specific", body, nil); w.Code != http.StatusCreated {
+			t.Fatalf("POST specific: %d %s", w.Code, w.Body.String())
+		}
+	}
+	for _, query := range []string{"host=m.example.com", "id=shop-mobile"} {
+		w := doRequest(router, "GET", "/api/specific?"+query, "", nil)
+		var cfg map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &cfg)
+		actions, _ := cfg["actions"].([]interface{})
+		hosts := asMap(asMap(cfg["datasource"])["hosts"])
+		if cfg["id"] != "shop-mobile" || describeActions(actions) != "remove:.ad,remove:.desktop" || len(hosts) != 2 {
+			t.Errorf("%s: %d %s", query, w.Code, w.Body.String())
+		}
+	}
+
+	// Pages: çocuk üstün page eşleşmelerini devralır ve priority ile önce gelir
+	for _, body := range []string{
+		`{"id": "blog", "datasource": {"pages": {"post": "post.yaml"}}, "actions": [{"type": "remove", "selector": ".ad"}]}`,
+		`{"id": "blog-v2", "extends": "blog", "priority": 1, "omit_actions": [{"type": "remove", "selector": ".ad"}],
+			"datasource": {"urls": {"/shop/": "shop.yaml"}}, "actions": [{"type": "remove", "selector": ".banner"}]}`,
+	} {
+		if w := doRequest(router, "POST", "/api/pages", body, nil); w.Code != http.StatusCreated {
+			t.Fatalf("POST pages: %d %s", w.Code, w.Body.String())
+		}
+	}
+	w := doRequest(router, "GET", "/api/pages/resolve?page=post", "", nil)
+	var res struct {
+		Config           PagesConfig   `json:"config"`
+		EffectiveActions []interface{} `json:"effective_actions"`
+	}
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &res) != nil {
+		t.Fatalf("resolve: %d %s", w.Code, w.Body.String())
+	}
+	if res.Config.ID != "blog-v2" || res.Config.Extends != "" || describeActions(res.EffectiveActions) != "remove:.banner" {
+		t.Errorf("resolve: %s", w.Body.String())
+	}
+
+	w = doRequest(router, "GET", "/api/pages/blog-v2/flattened", "", nil)
+	var flat PagesConfig
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &flat) != nil || len(flat.Datasource.Pages) != 1 || len(flat.Datasource.URLs) != 1 {
+		t.Errorf("pages flattened: %d %s", w.Code, w.Body.String())
+	}
+
+	if w := doRequest(router, "PUT", "/api/pages/blog", `{"extends": "blog-v2"}`, nil); w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), codeExtendsCycle) {
+		t.Errorf("pages cycle: %d %s", w.Code, w.Body.String())
+	}
+}
+
+// Devralınan HTML, üstün politikasıyla değil çocuğa uygulanan politikayla temizlenir
+func TestExtendsResanitizesInheritedActions(t *testing.T) {
+	useMemoryStore(t)
+	router := newRouter()
+
+	for _, req := range []struct{ url, body string }{
+		{"/api/policies", `{"id": "text", "elements": {"b": [], "i": []}}`},
+		{"/api/configuration", `{"id": "rich", "actions": [{"type": "replace", "selector": ".x", "newElement": "<div class=\"box\"><b>x</b></div>"}]}`},
+		{"/api/configuration", `{"id": "plain", "extends": "rich", "sanitizer_policy": "text", "actions": []}`},
+		{"/api/pages", `{"id": "base", "datasource": {"pages": {"post": "plain.yaml"}}, "actions": [
+			{"type": "insert", "target": "body", "position": "append", "element": "<div><i>y</i></div>"}]}`},
+		{"/api/pages", `{"id": "child", "extends": "base", "priority": 1, "sanitizer_policy": "text"}`},
+	} {
+		if w := doRequest(router, "POST", req.url, req.body, nil); w.Code != http.StatusCreated {
+			t.Fatalf("POST %s: %d %s", req.url, w.Code, w.Body.String())
+		}
+	}
+
+	w := doRequest(router, "GET", "/api/configuration/plain/flattened", "", nil)
+	var cfg map[string]interface{}
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &cfg) != nil {
+		t.Fatalf("flattened: %d %s", w.Code, w.Body.String())
+	}
+	actions, _ := cfg["actions"].([]interface{})
+	if got := describeActions(actions); got != "replace:.x=<b>x</b>" || cfg["applied_sanitizer_policy"] != "text" {
+		t.Errorf("flattened: %s", w.Body.String())
+	}
+
+	// Kazananın devraldığı aksiyon ve extends kullanan referansın aksiyonları da temizlenir
+	w = doRequest(router, "GET", "/api/pages/resolve?page=post", "", nil)
+	var res struct {
+		Config struct {
+			ID      string        `json:"id"`
+			Actions []interface{} `json:"actions"`
+		} `json:"config"`
+		EffectiveActions []interface{} `json:"effective_actions"`
+	}
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &res) != nil {
+		t.Fatalf("resolve: %d %s", w.Code, w.Body.String())
+	}
+	if got := describeActions(res.EffectiveActions); res.Config.ID != "child" || got != "insert:body=<i>y</i>,replace:.x=<b>x</b>" {
+		t.Errorf("resolve: %s", w.Body.String())
+	}
+	if got := describeActions(res.Config.Actions); got != "insert:body=<i>y</i>" {
+		t.Errorf("resolve config actions: %s", got)
+	}
+
+	// Üst config değişmez
+	w = doRequest(router, "GET", "/api/configuration/rich", "", nil)
+	if !strings.Contains(w.Body.String(), `class=\"box\"`) {
+		t.Errorf("parent changed: %s", w.Body.String())
+	}
+}
+
+// Extends edilen config silinemez; yine de bozulmuş zincirler atlanmaz, hata olarak döner
+func TestExtendsParentDeleteAndBrokenChains(t *testing.T) {
+	s := useMemoryStore(t)
+	putRefConfigs(t, s, "post.yaml")
+	router := newRouter()
+
+	for _, body := range []string{
+		`{"id": "shop", "datasource": {"hosts": {"shop.example.com": "x"}}, "actions": []}`,
+		`{"id": "shop-mobile", "extends": "shop", "datasource": {"hosts": {"m.example.com": "x"}}, "actions": []}`,
+	} {
+		if w := doRequest(router, "POST", "/api/specific", body, nil); w.Code != http.StatusCreated {
+			t.Fatalf("POST specific: %d %s", w.Code, w.Body.String())
+		}
+	}
+	w := doRequest(router, "DELETE", "/api/specific/shop", "", nil)
+	if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), `"extended_by":["shop-mobile"]`) {
+		t.Errorf("delete parent: %d %s", w.Code, w.Body.String())
+	}
+	if _, err := s.Get(KindSpecific, "shop"); err != nil {
+		t.Errorf("parent deleted: %v", err)
+	}
+
+	// Store'a doğrudan yazılmış (ya da eski sürümde bozulmuş) zincir
+	s.Put(KindSpecific, "orphan", []byte("id: orphan\nextends: gone\ndatasource:\n  hosts:\n    orphan.example.com: x\nactions: []\n"))
+	w = doRequest(router, "GET", "/api/specific?host=orphan.example.com", "", nil)
+	if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), codeMissingRef) {
+		t.Errorf("broken specific: %d %s", w.Code, w.Body.String())
+	}
+	if w := doRequest(router, "GET", "/api/specific?host=shop.example.com", "", nil); w.Code != http.StatusOK {
+		t.Errorf("unrelated specific: %d %s", w.Code, w.Body.String())
+	}
+
+	s.Put(KindPages, "orphan", []byte("id: orphan\nextends: gone\ndatasource:\n  pages:\n    post: post.yaml\nactions: []\n"))
+	w = doRequest(router, "GET", "/api/pages/resolve?page=post", "", nil)
+	if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), codeMissingRef) {
+		t.Errorf("broken pages: %d %s", w.Code, w.Body.String())
+	}
+
+	// Önce çocuk silinirse üst de silinebilir
+	for _, id := range []string{"shop-mobile", "shop"} {
+		if w := doRequest(router, "DELETE", "/api/specific/"+id, "", nil); w.Code != http.StatusOK {
+			t.Errorf("delete %s: %d %s", id, w.Code, w.Body.String())
+		}
+	}
+}
+
+// Üstün açıkça seçtiği politika çocuğa geçmez; çocuğun politikası kendi alanlarından çözülür
+func TestExtendsDoesNotInheritPolicy(t *testing.T) {
+	useMemoryStore(t)
+	router := newRouter()
+
+	for _, req := range []struct{ url, body string }{
+		{"/api/policies", `{"id": "rich", "elements": {"div": ["class"], "b": []}}`},
+		{"/api/policies", `{"id": "text", "hosts": ["m.example.com"], "elements": {"b": []}}`},
+		{"/api/specific", `{"id": "shop", "sanitizer_policy": "rich", "actions": [
+			{"type": "replace", "selector": ".x", "newElement": "<div class=\"box\"><b>x</b></div>"}]}`},
+		{"/api/specific", `{"id": "shop-mobile", "extends": "shop", "datasource": {"hosts": {"m.example.com": "x"}}, "actions": []}`},
+	} {
+		if w := doRequest(router, "POST", req.url, req.body, nil); w.Code != http.StatusCreated {
+			t.Fatalf("POST %s: %d %s", req.url, w.Code, w.Body.String())
+		}
+	}
+
+	for _, url := range []string{"/api/specific/shop-mobile/flattened", "/api/specific?host=m.example.com"} {
+		w := doRequest(router, "GET", url, "", nil)
+		var cfg map[string]interface{}
+		if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &cfg) != nil {
+			t.Fatalf("%s: %d %s", url, w.Code, w.Body.String())
+		}
+		actions, _ := cfg["actions"].([]interface{})
+		if cfg["id"] != "shop-mobile" || cfg["sanitizer_policy"] != nil || cfg["applied_sanitizer_policy"] != "text" || describeActions(actions) != "replace:.x=<b>x</b>" {
+			t.Errorf("%s: %s", url, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"gopkg.in/yaml.v3"
+)
+
+// İsteği yapan kullanıcı (X-User başlığı; yoksa "anonymous")
+func requestAuthor(r *http.Request) string {
+	if user := r.Header.Get("X-User"); user != "" {
+		return user
+	}
+	return "anonymous"
+}
+
+// Konfigürasyonu yaz ve yeni revizyonu aynı transaction içinde kaydet
+func writeConfig(kind ConfigKind, id string, data []byte, author string) error {
+	return withTx(store, func(tx ConfigStore) error {
+		action := "update"
+		if _, err := tx.Get(kind, id); err == ErrConfigNotFound {
+			action = "create"
+		}
+		if err := tx.Put(kind, id, data); err != nil {
+			return err
+		}
+		_, err := tx.AddRevision(kind, id, Revision{Action: action, Author: author, Timestamp: time.Now().UTC(), Data: data})
+		return err
+	})
+}
+
+// API yanıtındaki revizyon özeti
+type revisionInfo struct {
+	Revision
+	ETag string `json:"etag,omitempty"`
+}
+
+// ETag, revizyonun şu an GET ile sunulacak halinden hesaplanır (bkz. renderConfig)
+func newRevisionInfo(kind ConfigKind, rev Revision) revisionInfo {
+	info := revisionInfo{Revision: rev}
+	if len(rev.Data) > 0 {
+		if b, err := renderConfigData(kind, rev.Data); err == nil {
+			info.ETag = configETag(b)
+		}
+	}
+	return info
+}
+
+// URL'deki {rev} parametresi
+func revisionNumber(r *http.Request) (int, bool) {
+	n, err := strconv.Atoi(mux.Vars(r)["rev"])
+	return n, err == nil && n > 0
+}
+
+// GET /api/{configuration|specific|pages}/{id}/history
+func handleListHistory(kind ConfigKind) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := mux.Vars(r)["id"]
+		revs, err := store.Revisions(kind, id)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error": "Revizyonlar okunamadı"}`))
+			return
+		}
+		if len(revs) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": "Revizyon geçmişi bulunamadı"}`))
+			return
+		}
+		infos := make([]revisionInfo, 0, len(revs))
+		for _, rev := range revs {
+			infos = append(infos, revisionInfo{Revision: rev})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(infos)
+	}
+}
+
+// GET /api/{configuration|specific|pages}/{id}/history/{rev}
+func handleGetRevision(kind ConfigKind) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := mux.Vars(r)["id"]
+		n, ok := revisionNumber(r)
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": "Geçersiz revizyon numarası"}`))
+			return
+		}
+		rev, err := store.GetRevision(kind, id, n)
+		if err == ErrRevisionNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": "Revizyon bulunamadı"}`))
+			return
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error": "Revizyon okunamadı"}`))
+			return
+		}
+		var cfg Config
+		if len(rev.Data) > 0 {
+			if cfg, err = parseConfig(rev.Data); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(`{"error": "YAML parse hatası"}`))
+				return
+			}
+		}
+		result := map[string]interface{}{
+			"revision": newRevisionInfo(kind, *rev),
+			"config":   cfg,
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	}
+}
+
+// POST /api/{configuration|specific|pages}/{id}/history/{rev}/rollback
+// Seçilen revizyonun içeriğini yeni bir revizyon olarak geri yükler
+func handleRollback(kind ConfigKind) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := mux.Vars(r)["id"]
+		n, ok := revisionNumber(r)
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": "Geçersiz revizyon numarası"}`))
+			return
+		}
+		defer lockConfig(kind, id)()
+		if !checkIfMatch(w, r, kind, id) {
+			return
+		}
+		rev, err := store.GetRevision(kind, id, n)
+		if err == ErrRevisionNotFound {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": "Revizyon bulunamadı"}`))
+			return
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error": "Revizyon okunamadı"}`))
+			return
+		}
+		if len(rev.Data) == 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": "Silme revizyonuna geri dönülemez"}`))
+			return
+		}
+		// Eski revizyon bugünkü kurallara (şema, extends, politika) uymayabilir; kayıttaki gibi
+		// doğrulanıp temizlenir
+		report, data, err := prepareRollback(kind, id, rev.Data)
+		if err != nil {
+			if !writeValidationError(w, err) {
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(`{"error": "Revizyon doğrulanamadı"}`))
+			}
+			return
+		}
+		var added Revision
+		err = withTx(store, func(tx ConfigStore) error {
+			if err := tx.Put(kind, id, data); err != nil {
+				return err
+			}
+			added, err = tx.AddRevision(kind, id, Revision{
+				Action:       "rollback",
+				Author:       requestAuthor(r),
+				Timestamp:    time.Now().UTC(),
+				RestoredFrom: n,
+				Data:         data,
+			})
+			return err
+		})
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error": "Geri alma başarısız"}`))
+			return
+		}
+		setStoredETag(w, kind, id)
+		w.Header().Set("Content-Type", "application/json")
+		if report == nil {
+			report = []ActionSanitizeReport{}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"message":   "Config geri alındı",
+			"revision":  newRevisionInfo(kind, added),
+			"sanitized": report,
+		})
+	}
+}
+
+// Revizyon verisini POST/PUT'taki kayıt yolundan geçir: şema, extends ve aksiyon doğrulaması,
+// güncel politikayla temizleme. Saklanacak baytları döndürür.
+func prepareRollback(kind ConfigKind, id string, data []byte) ([]ActionSanitizeReport, []byte, error) {
+	cfg, err := parseConfig(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Şema JSON tiplerini bekler (ör. sayılar float64)
+	var body map[string]interface{}
+	b, err := json.Marshal(normalizeValue(cfg))
+	if err == nil {
+		err = json.Unmarshal(b, &body)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	body["id"] = id
+	if errs := checkBody(kind, body); len(errs) > 0 {
+		return nil, nil, errs
+	}
+	if kind == KindPages {
+		var pc PagesConfig
+		if b, err = json.Marshal(body); err == nil {
+			err = json.Unmarshal(b, &pc)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		return preparePagesConfig(&pc, false)
+	}
+	if err := validateExtends(kind, Config(body)); err != nil {
+		return nil, nil, err
+	}
+	report, err := prepareConfigActions(Config(body), false)
+	if err != nil {
+		return nil, nil, err
+	}
+	if b, err = yaml.Marshal(body); err != nil {
+		return nil, nil, err
+	}
+	return report, b, nil
+}
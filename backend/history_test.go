@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestConfigHistoryAndRollback(t *testing.T) {
+	useMemoryStore(t)
+	router := newRouter()
+
+	v1 := `{"id": "demo", "actions": [{"type": "remove", "selector": ".ad"}]}`
+	v2 := `{"id": "demo", "actions": [{"type": "remove", "selector": ".banner"}]}`
+	if w := doRequest(router, "POST", "/api/configuration", v1, map[string]string{"X-User": "alice"}); w.Code != http.StatusCreated {
+		t.Fatalf("POST: %d %s", w.Code, w.Body.String())
+	}
+	if w := doRequest(router, "PUT", "/api/configuration/demo", v2, map[string]string{"X-User": "bob"}); w.Code != http.StatusOK {
+		t.Fatalf("PUT: %d %s", w.Code, w.Body.String())
+	}
+
+	w := doRequest(router, "GET", "/api/configuration/demo/history", "", nil)
+	var revs []revisionInfo
+	if err := json.Unmarshal(w.Body.Bytes(), &revs); err != nil || len(revs) != 2 {
+		t.Fatalf("history: %d %s", w.Code, w.Body.String())
+	}
+	if revs[0].Action != "create" || revs[0].Author != "alice" || revs[1].Action != "update" || revs[1].Author != "bob" {
+		t.Errorf("unexpected revisions: %+v", revs)
+	}
+
+	w = doRequest(router, "GET", "/api/configuration/demo/history/1", "", nil)
+	var got struct {
+		Revision revisionInfo `json:"revision"`
+		Config   Config       `json:"config"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("get revision: %d %s", w.Code, w.Body.String())
+	}
+	if got.Revision.Number != 1 || got.Revision.ETag == "" {
+		t.Errorf("revision meta = %+v", got.Revision)
+	}
+
+	w = doRequest(router, "POST", "/api/configuration/demo/history/1/rollback", "", map[string]string{"X-User": "carol"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("rollback: %d %s", w.Code, w.Body.String())
+	}
+	cfg, err := loadConfig(KindGeneral, "demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := json.Marshal(cfg["actions"]); string(b) != `[{"selector":".ad","type":"remove"}]` {
+		t.Errorf("after rollback actions = %s", b)
+	}
+	stored, _ := store.Revisions(KindGeneral, "demo")
+	if len(stored) != 3 || stored[2].Action != "rollback" || stored[2].RestoredFrom != 1 || stored[2].Author != "carol" {
+		t.Errorf("rollback revision = %+v", stored)
+	}
+
+	// Silme de revizyon olarak kaydedilir ama ona geri dönülemez
+	doRequest(router, "DELETE", "/api/configuration/demo", "", nil)
+	if w := doRequest(router, "POST", "/api/configuration/demo/history/4/rollback", "", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("rollback to delete revision: want 400, got %d", w.Code)
+	}
+	if w := doRequest(router, "POST", "/api/configuration/demo/history/2/rollback", "", nil); w.Code != http.StatusOK {
+		t.Errorf("rollback after delete: want 200, got %d", w.Code)
+	}
+	if w := doRequest(router, "GET", "/api/configuration/demo/history/99", "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("missing revision: want 404, got %d", w.Code)
+	}
+}
+
+// Geri alınan revizyon kayıttaki gibi doğrulanır ve güncel politikayla temizlenir
+func TestRollbackRevalidates(t *testing.T) {
+	s := useMemoryStore(t)
+	router := newRouter()
+
+	v1 := `{"id": "shop", "datasource": {"hosts": {"shop.example.com": "x"}},
+		"actions": [{"type": "replace", "selector": ".x", "newElement": "<div class=\"box\"><b>x</b></div>"}]}`
+	if w := doRequest(router, "POST", "/api/configuration", v1, nil); w.Code != http.StatusCreated {
+		t.Fatalf("POST: %d %s", w.Code, w.Body.String())
+	}
+	if w := doRequest(router, "PUT", "/api/configuration/shop", `{"actions": []}`, nil); w.Code != http.StatusOK {
+		t.Fatalf("PUT: %d %s", w.Code, w.Body.String())
+	}
+	if w := doRequest(router, "POST", "/api/policies", `{"id": "text", "hosts": ["shop.example.com"], "elements": {"b": []}}`, nil); w.Code != http.StatusCreated {
+		t.Fatalf("POST policy: %d %s", w.Code, w.Body.String())
+	}
+	w := doRequest(router, "POST", "/api/configuration/shop/history/1/rollback", "", nil)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"field":"newElement"`) {
+		t.Fatalf("rollback: %d %s", w.Code, w.Body.String())
+	}
+	if b, _ := s.Get(KindGeneral, "shop"); strings.Contains(string(b), "box") || !strings.Contains(string(b), "<b>x</b>") {
+		t.Errorf("stored after rollback:\n%s", b)
+	}
+
+	// Bugünkü şemaya uymayan revizyon geri yüklenmez
+	rev, err := s.AddRevision(KindGeneral, "shop", Revision{Action: "update", Data: []byte("id: shop\nactions:\n  - type: remove\n    selector: \"[\"\n")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w = doRequest(router, "POST", fmt.Sprintf("/api/configuration/shop/history/%d/rollback", rev.Number), "", nil)
+	if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), codeInvalidSelector) {
+		t.Errorf("invalid revision: %d %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// Datasource.Hosts anahtarlarının eşleşmesi:
+//
+//	blog.example.com   yalnızca blog.example.com
+//	*.example.com      example.com'un tüm alt alan adları (www.blog.example.com dahil, example.com hariç)
+//
+// Karşılaştırma büyük/küçük harften ve porttan bağımsızdır; Unicode alan adları (IDN)
+// her iki tarafta da IDNA (UTS #46) ile punycode (xn--) biçimine çevrilerek karşılaştırılır.
+
+// hostPattern: Datasource.Hosts'taki bir anahtarın çözümlenmiş hali
+type hostPattern struct {
+	Key      string
+	host     string // normalize edilmiş host; joker için "*." sonrası
+	wildcard bool
+}
+
+func parseHostPattern(key string) *hostPattern {
+	h := normalizeHost(key)
+	if strings.HasPrefix(h, "*.") {
+		return &hostPattern{Key: key, host: h[2:], wildcard: true}
+	}
+	return &hostPattern{Key: key, host: h}
+}
+
+// host normalize edilmiş olmalı
+func (p *hostPattern) match(host string) bool {
+	if p.wildcard {
+		return strings.HasSuffix(host, "."+p.host)
+	}
+	return host != "" && host == p.host
+}
+
+// p'nin q'dan daha özgül (>0), eşit (0) ya da daha genel (<0) olduğu:
+// birebir > joker; jokerler arasında daha uzun alan adı
+func (p *hostPattern) specificity(q *hostPattern) int {
+	if p.wildcard != q.wildcard {
+		if p.wildcard {
+			return -1
+		}
+		return 1
+	}
+	return len(p.host) - len(q.host)
+}
+
+// Hostu karşılaştırılabilir biçime getir: küçük harf, port ve sondaki nokta atılır, IDN'ler UTS #46'ya göre ASCII'ye (xn--) çevrilir.
+// Tam URL verilirse yalnız host kısmı alınır.
+func normalizeHost(h string) string {
+	h = strings.ToLower(strings.TrimSpace(h))
+	if i := strings.Index(h, "://"); i >= 0 {
+		h = h[i+3:]
+	}
+	if i := strings.IndexAny(h, "/?#"); i >= 0 {
+		h = h[:i]
+	}
+	if strings.HasPrefix(h, "[") {
+		// IPv6: [::1]:8080
+		if i := strings.Index(h, "]"); i >= 0 {
+			return h[:i+1]
+		}
+		return h
+	}
+	if strings.Count(h, ":") == 1 {
+		h = h[:strings.Index(h, ":")]
+	}
+	// Joker öneki IDNA etiketi değildir; dönüşümden önce ayrılır
+	prefix := ""
+	if strings.HasPrefix(h, "*.") {
+		prefix, h = "*.", h[2:]
+	}
+	// UTS #46: NFC, genişletilmiş noktalar (。) ve punycode; dönüşemeyen host olduğu gibi karşılaştırılır
+	if a, err := idna.Lookup.ToASCII(h); err == nil {
+		h = a
+	}
+	return prefix + strings.TrimSuffix(h, ".")
+}
+
+// Bir hosts eşlemesinde hosta uyan en özgül anahtar
+func bestHostPattern(hosts map[string]interface{}, host string) *hostPattern {
+	host = normalizeHost(host)
+	keys := make([]string, 0, len(hosts))
+	for k := range hosts {
+		keys = append(keys, k)
+	}
+	// Aynı hosta normalize olan anahtarlarda sonuç sıradan bağımsız olsun
+	sort.Strings(keys)
+	var best *hostPattern
+	for _, k := range keys {
+		p := parseHostPattern(k)
+		if p.match(host) && (best == nil || p.specificity(best) > 0) {
+			best = p
+		}
+	}
+	return best
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestNormalizeHost(t *testing.T) {
+	tests := map[string]string{
+		"Blog.Example.COM":               "blog.example.com",
+		"blog.example.com:8080":          "blog.example.com",
+		"blog.example.com.":              "blog.example.com",
+		"https://blog.example.com:443/x": "blog.example.com",
+		"[::1]:8080":                     "[::1]",
+		"bücher.example":                 "xn--bcher-kva.example",
+		"MÜNCHEN.de":                     "xn--mnchen-3ya.de",
+		"xn--bcher-kva.example":          "xn--bcher-kva.example",
+		"*.Bücher.example":               "*.xn--bcher-kva.example",
+		"例え.テスト":                         "xn--r8jz45g.xn--zckzah",
+		"bu\u0308cher.de":                "xn--bcher-kva.de",
+		"a。b.com":                        "a.b.com",
+		"ｂｌｏｇ.example.com":               "blog.example.com",
+	}
+	for in, want := range tests {
+		if got := normalizeHost(in); got != want {
+			t.Errorf("normalizeHost(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestHostPatternMatch(t *testing.T) {
+	tests := []struct {
+		key, host string
+		match     bool
+	}{
+		{"blog.example.com", "BLOG.example.com:3000", true},
+		{"blog.example.com", "www.blog.example.com", false},
+		{"*.example.com", "www.blog.example.com", true},
+		{"*.example.com", "example.com", false},
+		{"*.example.com", "badexample.com", false},
+		{"localhost:3000", "localhost:8080", true},
+		{"*.bücher.example", "shop.xn--bcher-kva.example", true},
+	}
+	for _, tt := range tests {
+		if got := parseHostPattern(tt.key).match(normalizeHost(tt.host)); got != tt.match {
+			t.Errorf("%q ~ %q = %v, want %v", tt.key, tt.host, got, tt.match)
+		}
+	}
+}
+
+func TestResolveAndSpecificHostMatching(t *testing.T) {
+	s := useMemoryStore(t)
+	putRefConfigs(t, s, "root.yaml", "sub.yaml", "main.yaml", "idn.yaml")
+	router := newRouter()
+
+	for _, body := range []string{
+		`{"id": "root", "datasource": {"hosts": {"*.example.com": "root.yaml"}}}`,
+		`{"id": "blog", "datasource": {"hosts": {"*.blog.example.com": "sub.yaml", "Blog.Example.com:8080": "main.yaml"}}}`,
+		`{"id": "idn", "datasource": {"hosts": {"bücher.example": "idn.yaml"}}}`,
+	} {
+		if w := doRequest(router, "POST", "/api/pages", body, nil); w.Code != http.StatusCreated {
+			t.Fatalf("POST pages: %d %s", w.Code, w.Body.String())
+		}
+	}
+	tests := []struct {
+		host, config, ref, matchedBy string
+	}{
+		{"blog.example.com", "blog", "main.yaml", "host"},
+		{"BLOG.EXAMPLE.COM:443", "blog", "main.yaml", "host"},
+		{"www.blog.example.com", "blog", "sub.yaml", "host_wildcard"},
+		{"shop.example.com", "root", "root.yaml", "host_wildcard"},
+		{"xn--bcher-kva.example", "idn", "idn.yaml", "host"},
+	}
+	for _, tt := range tests {
+		w := doRequest(router, "GET", "/api/pages/resolve?host="+tt.host, "", nil)
+		var res struct {
+			Config    PagesConfig `json:"config"`
+			MatchedBy string      `json:"matched_by"`
+			ConfigRef string      `json:"config_ref"`
+		}
+		if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &res) != nil {
+			t.Errorf("%s: %d %s", tt.host, w.Code, w.Body.String())
+			continue
+		}
+		if res.Config.ID != tt.config || res.ConfigRef != tt.ref || res.MatchedBy != tt.matchedBy {
+			t.Errorf("%s: got %s %s %s, want %s %s %s", tt.host, res.Config.ID, res.ConfigRef, res.MatchedBy, tt.config, tt.ref, tt.matchedBy)
+		}
+	}
+	if w := doRequest(router, "GET", "/api/pages/resolve?host=example.com", "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("apex matched wildcard: %d %s", w.Code, w.Body.String())
+	}
+
+	// Spesifik configler store'dan (YAML) okunur; iç içe map'ler de eşleşmeli
+	for _, body := range []string{
+		`{"id": "any", "datasource": {"hosts": {"*.shop.example.com": "x"}}, "actions": []}`,
+		`{"id": "mobile", "datasource": {"hosts": {"m.shop.example.com": "x"}}, "actions": []}`,
+		`{"id": "paths", "datasource": {"urls": {"/cart": "x"}}, "actions": []}`,
+	} {
+		if w := doRequest(router, "POST", "/api/specific", body, nil); w.Code != http.StatusCreated {
+			t.Fatalf("POST specific: %d %s", w.Code, w.Body.String())
+		}
+	}
+	for host, want := range map[string]string{"M.Shop.Example.com:8443": "mobile", "www.shop.example.com": "any"} {
+		w := doRequest(router, "GET", "/api/specific?host="+host, "", nil)
+		var cfg map[string]interface{}
+		if json.Unmarshal(w.Body.Bytes(), &cfg); cfg["id"] != want {
+			t.Errorf("specific host %s: %d %s, want %s", host, w.Code, w.Body.String(), want)
+		}
+	}
+	w := doRequest(router, "GET", "/api/specific?url=/cart", "", nil)
+	var cfg map[string]interface{}
+	if json.Unmarshal(w.Body.Bytes(), &cfg); cfg["id"] != "paths" {
+		t.Errorf("specific url: %d %s", w.Code, w.Body.String())
+	}
+}
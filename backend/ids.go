@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// Bir config id'sinin en fazla uzunluğu
+const maxConfigIDLength = 64
+
+var (
+	ErrInvalidID    = errors.New("geçersiz id")
+	errIDEmpty      = errors.New("id boş olamaz")
+	errIDTooLong    = errors.New("id en fazla 64 karakter olabilir")
+	errIDCharset    = errors.New("id yalnızca harf, rakam, '-' ve '_' içerebilir ve harf/rakam ile başlamalı")
+	errIDReservedPx = errors.New("genel config id'si 'pages_', 'specific_' veya 'policy_' ile başlayamaz")
+)
+
+// invalidIDError, id reddedilme sebebini ErrInvalidID ile sarmalar
+type invalidIDError struct {
+	reason error
+}
+
+func (e *invalidIDError) Error() string { return ErrInvalidID.Error() + ": " + e.reason.Error() }
+func (e *invalidIDError) Unwrap() error { return ErrInvalidID }
+
+// validateConfigID, id'nin dosya adı, URL parçası ve veritabanı anahtarı olarak güvenli olduğunu denetler.
+// Nokta ve ayraç karakterleri kabul edilmediği için "../" gibi yol geçişleri mümkün değildir.
+func validateConfigID(kind ConfigKind, id string) error {
+	if id == "" {
+		return &invalidIDError{errIDEmpty}
+	}
+	if len(id) > maxConfigIDLength {
+		return &invalidIDError{errIDTooLong}
+	}
+	for i, c := range id {
+		alnum := c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+		if !alnum && (i == 0 || c != '-' && c != '_') {
+			return &invalidIDError{errIDCharset}
+		}
+	}
+	// FileStore'da genel configler önek almaz; bu önekler diğer türlerle çakışır
+	if kind == KindGeneral {
+		for _, prefix := range []string{"pages_", "specific_", "policy_"} {
+			if strings.HasPrefix(id, prefix) {
+				return &invalidIDError{errIDReservedPx}
+			}
+		}
+	}
+	return nil
+}
+
+// Geçersiz id için 400 yanıtı
+func writeInvalidID(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+// withValidID, route'taki {id} değişkenini handler'a ulaşmadan doğrular
+func withValidID(kind ConfigKind, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := validateConfigID(kind, mux.Vars(r)["id"]); err != nil {
+			writeInvalidID(w, err)
+			return
+		}
+		h(w, r)
+	}
+}
@@ -1,15 +1,12 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
-	"io/ioutil"
-	"os"
-	"path/filepath"
 	"encoding/json"
 	"gopkg.in/yaml.v3"
-	"regexp"
 	"github.com/gorilla/mux"
 	"github.com/rs/cors"
 	"time"
@@ -23,8 +20,20 @@ type PagesConfig struct {
 	ID         string                 `yaml:"id" json:"id"`
 	Name       string                 `yaml:"name" json:"name"`
 	Datasource PagesDataSource        `yaml:"datasource" json:"datasource"`
-	Actions    []interface{}          `yaml:"actions" json:"actions"`
+	Actions    Actions                `yaml:"actions" json:"actions"`
 	Metadata   map[string]interface{} `yaml:"metadata,omitempty" json:"metadata,omitempty"`
+	// Aynı türde ve eşit özgüllükte eşleşen configler arasında büyük olan kazanır (bkz. rankResolveCandidates)
+	Priority int `yaml:"priority,omitempty" json:"priority,omitempty"`
+	// newElement/element için seçilen sanitizer politikası (boşsa datasource hostlarına göre seçilir)
+	SanitizerPolicy string `yaml:"sanitizer_policy,omitempty" json:"sanitizer_policy,omitempty"`
+	// Config'e uygulanan politika; saklanmaz, GET yanıtlarında sunucu hesaplar (bkz. withAppliedPolicy)
+	AppliedSanitizerPolicy string `yaml:"-" json:"applied_sanitizer_policy,omitempty"`
+	// Aksiyonları ve datasource'u devralınan config (bkz. flattenConfig)
+	Extends string `yaml:"extends,omitempty" json:"extends,omitempty"`
+	// extends ile gelip bu config'te istenmeyen aksiyonlar
+	OmitActions []ActionKey `yaml:"omit_actions,omitempty" json:"omit_actions,omitempty"`
+	// Kayıtlı config'in güncel kurallara uymayan yerleri; saklanmaz, okurken hesaplanır (bkz. parsePagesConfig)
+	Problems ValidationErrors `yaml:"-" json:"validation_errors,omitempty"`
 }
 
 type PagesDataSource struct {
@@ -36,142 +45,201 @@ type PagesDataSource struct {
 // Yardımcı: configs klasörü yolu
 const configDir = "configs"
 
-// Yardımcı: id'den dosya yolu
-func configPath(id string) string {
-	return filepath.Join(configDir, id+".yaml")
+// Konfigürasyonların saklandığı katman (varsayılan: configs klasörü)
+var store ConfigStore = NewFileStore(configDir)
+
+// YAML içeriğini genel Config olarak parse et
+func parseConfig(b []byte) (Config, error) {
+	var cfg Config
+	if err := yaml.Unmarshal(b, &cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// YAML içeriğini PagesConfig olarak parse et
+// Kayıtlı pages config'i oku. Aksiyonlar güncel kurallara uymasa da okunur (bkz.
+// decodeStoredActions); sorunlar Problems alanında döner, config kaybolmaz.
+func parsePagesConfig(b []byte) (*PagesConfig, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	var actions *yaml.Node
+	if len(doc.Content) == 1 && doc.Content[0].Kind == yaml.MappingNode {
+		m := doc.Content[0]
+		for i := 0; i+1 < len(m.Content); i += 2 {
+			if m.Content[i].Value == "actions" {
+				actions = m.Content[i+1]
+				m.Content = append(m.Content[:i:i], m.Content[i+2:]...)
+				break
+			}
+		}
+	}
+	var cfg PagesConfig
+	if err := doc.Decode(&cfg); err != nil {
+		return nil, err
+	}
+	if actions != nil {
+		cfg.Actions, cfg.Problems = decodeStoredActions(actions)
+	}
+	return &cfg, nil
 }
 
-// Yardımcı: spesifik config dosya yolu
-func specificConfigPath(id string) string {
-	return filepath.Join(configDir, "specific_"+id+".yaml")
+// Derin kopya (cache'teki nesnenin çağıran tarafından değiştirilmemesi için)
+func (c *PagesConfig) clone() *PagesConfig {
+	if c == nil {
+		return nil
+	}
+	out := *c
+	out.Datasource.Pages, _ = cloneValue(c.Datasource.Pages).(map[string]interface{})
+	out.Datasource.URLs, _ = cloneValue(c.Datasource.URLs).(map[string]interface{})
+	out.Datasource.Hosts, _ = cloneValue(c.Datasource.Hosts).(map[string]interface{})
+	out.Actions = c.Actions.clone()
+	out.OmitActions = append([]ActionKey(nil), c.OmitActions...)
+	out.Metadata, _ = cloneValue(c.Metadata).(map[string]interface{})
+	return &out
 }
 
-// Yardımcı: pages config dosya yolu
-func pagesConfigPath(id string) string {
-	return filepath.Join(configDir, "pages_"+id+".yaml")
+// Genel/spesifik konfigürasyonu yükle (store cache'liyse parse edilmiş kopya kullanılır)
+func loadConfig(kind ConfigKind, id string) (Config, error) {
+	if ps, ok := store.(parsedConfigSource); ok {
+		return ps.ParsedConfig(kind, id)
+	}
+	b, err := store.Get(kind, id)
+	if err != nil {
+		return nil, err
+	}
+	return parseConfig(b)
 }
 
 // Pages konfigürasyonunu yükle
 func loadPagesConfig(id string) (*PagesConfig, error) {
-	b, err := ioutil.ReadFile(pagesConfigPath(id))
+	if ps, ok := store.(parsedConfigSource); ok {
+		return ps.ParsedPages(id)
+	}
+	b, err := store.Get(KindPages, id)
 	if err != nil {
 		return nil, err
 	}
-	var cfg PagesConfig
-	if err := yaml.Unmarshal(b, &cfg); err != nil {
+	return parsePagesConfig(b)
+}
+
+// Pages konfigürasyonunu kaydet; strict ise HTML'den bir şey atılması doğrulama hatasıdır
+func savePagesConfig(cfg *PagesConfig, author string, strict bool) ([]ActionSanitizeReport, error) {
+	report, b, err := preparePagesConfig(cfg, strict)
+	if err != nil {
 		return nil, err
 	}
-	return &cfg, nil
+	return report, writeConfig(KindPages, cfg.ID, b, author)
 }
 
-// Pages konfigürasyonunu kaydet
-func savePagesConfig(cfg *PagesConfig) error {
+// Pages config'i doğrula, aksiyonlarını temizle ve saklanacak YAML'i döndür
+func preparePagesConfig(cfg *PagesConfig, strict bool) ([]ActionSanitizeReport, []byte, error) {
+	sanitizer, err := resolveSanitizer(cfg.SanitizerPolicy, cfg.hosts())
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := validateURLPatterns(cfg.Datasource.URLs); err != nil {
+		return nil, nil, err
+	}
+	if err := validateExtends(KindPages, Config(asMap(normalizeValue(cfg)))); err != nil {
+		return nil, nil, err
+	}
 	// Actions validasyonu
-	if cfg.Actions != nil {
-		valid, sanitized := validateAndSanitizeActions(cfg.Actions)
-		if !valid {
-			return fmt.Errorf("geçersiz actions")
-		}
-		cfg.Actions = sanitized
+	report, err := validateAndSanitizeActions(cfg.Actions, sanitizer, strict)
+	if err != nil {
+		return nil, nil, err
 	}
-	
 	b, err := yaml.Marshal(cfg)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
-	return ioutil.WriteFile(pagesConfigPath(cfg.ID), b, 0644)
+	return report, b, nil
 }
 
 // Tüm pages konfigürasyonlarını listele
 func getAllPagesConfigs() ([]PagesConfig, error) {
-	files, err := ioutil.ReadDir(configDir)
+	ids, err := store.List(KindPages)
 	if err != nil {
 		return nil, err
 	}
 	
 	var configs []PagesConfig
-	for _, file := range files {
-		if filepath.Ext(file.Name()) == ".yaml" && 
-		   len(file.Name()) > 6 && file.Name()[:6] == "pages_" {
-			id := file.Name()[6 : len(file.Name())-5] // "pages_" ve ".yaml" kaldır
-			if cfg, err := loadPagesConfig(id); err == nil {
-				configs = append(configs, *cfg)
-			}
+	for _, id := range ids {
+		if cfg, err := loadPagesConfig(id); err == nil {
+			configs = append(configs, *cfg)
 		}
 	}
 	return configs, nil
 }
 
-// Basit HTML sanitizer: <script>, <style> ve on* eventlerini kaldırır
-func sanitizeHTML(input string) string {
-	reScript := regexp.MustCompile(`(?i)<script.*?>.*?</script>`) // script tag
-	reStyle := regexp.MustCompile(`(?i)<style.*?>.*?</style>`)   // style tag
-	reOnEventDouble := regexp.MustCompile(`(?i)on\w+\s*=\s*"[^"]*"`)
-	reOnEventSingle := regexp.MustCompile(`(?i)on\w+\s*=\s*'[^']*'`)
-	out := reScript.ReplaceAllString(input, "")
-	out = reStyle.ReplaceAllString(out, "")
-	out = reOnEventDouble.ReplaceAllString(out, "")
-	out = reOnEventSingle.ReplaceAllString(out, "")
-	return out
-}
-
-// Basit CSS selector validasyonu (class, id, tag)
-func isValidSelector(sel string) bool {
-	if sel == "" { return false }
-	// Sadece harf, rakam, . # ve - _ : [ ] karakterlerine izin ver
-	re := regexp.MustCompile(`^[a-zA-Z0-9\.\#\-\_\:\[\]=\'\"]+$`)
-	return re.MatchString(sel)
-}
-
-// Actions validasyonu ve sanitizasyonu
-func validateAndSanitizeActions(actions []interface{}) (bool, []interface{}) {
-	var sanitized []interface{}
-	for _, a := range actions {
-		act, ok := a.(map[string]interface{})
-		if !ok { return false, nil }
-		typeStr, _ := act["type"].(string)
-		switch typeStr {
-		case "remove", "replace", "insert":
-			// selector veya target kontrolü
-			if sel, ok := act["selector"].(string); ok && !isValidSelector(sel) {
-				return false, nil
-			}
-			if tgt, ok := act["target"].(string); ok && !isValidSelector(tgt) {
-				return false, nil
-			}
-			// HTML içeriği sanitize et
-			if html, ok := act["newElement"].(string); ok {
-				act["newElement"] = sanitizeHTML(html)
-			}
-			if html, ok := act["element"].(string); ok {
-				act["element"] = sanitizeHTML(html)
-			}
-		case "alter":
-			// metin değişimi, ek kontrol gerekmez
-		default:
-			return false, nil
+// Actions validasyonu ve sanitizasyonu (HTML içerikler verilen politikayla yerinde temizlenir).
+// Atılan yapılar aksiyon ve alan bazında raporlanır; strict ise içerik değiştirilmez,
+// her atma doğrulama hatası olur. Tüm aksiyonlardaki sorunlar birlikte döner.
+func validateAndSanitizeActions(actions Actions, sanitizer *Sanitizer, strict bool) ([]ActionSanitizeReport, error) {
+	var errs actionErrors
+	var report []ActionSanitizeReport
+	sanitize := func(i int, field string, value *string, clean func(string) (string, []RemovedConstruct)) {
+		out, removed := clean(*value)
+		if len(removed) > 0 && strict {
+			errs = append(errs, &actionError{Index: i, Field: field, Code: codeUnsafeHTML, Message: "izin verilmeyen içerik: " + describeRemovals(removed)})
+			return
 		}
-		sanitized = append(sanitized, act)
+		*value = out
+		if len(removed) > 0 {
+			report = append(report, ActionSanitizeReport{Index: i, Field: field, Removed: removed})
+		}
+	}
+	for i, act := range actions {
+		for _, e := range act.check() {
+			e.Index = i
+			errs = append(errs, e)
+		}
+		switch a := act.(type) {
+		case *ReplaceAction:
+			sanitize(i, "newElement", &a.NewElement, sanitizer.SanitizeWithReport)
+		case *InsertAction:
+			sanitize(i, "element", &a.Element, sanitizer.SanitizeWithReport)
+		case *AlterAction:
+			// newValue düz metindir; politika ne olursa olsun işaretleme atılır
+			sanitize(i, "newValue", &a.NewValue, stripMarkup)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
 	}
-	return true, sanitized
+	return report, nil
 }
 
 // GET /api/configuration/all
 func handleGetAllConfigs(w http.ResponseWriter, r *http.Request) {
-	files, err := ioutil.ReadDir(configDir)
+	ps, _ := loadPolicySet()
+	// Pages datasource'larının referans verdiği genel configler yalnızca kendi sayfalarında uygulanır
+	refs, err := referencedConfigIDs()
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Write([]byte(`{"error": "Config klasörü okunamadı"}`))
 		return
 	}
 	var configs []Config
-	for _, file := range files {
-		if filepath.Ext(file.Name()) == ".yaml" {
-			b, err := ioutil.ReadFile(filepath.Join(configDir, file.Name()))
+	for _, kind := range configKinds {
+		if kind == KindPolicy {
+			continue
+		}
+		ids, err := store.List(kind)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error": "Config klasörü okunamadı"}`))
+			return
+		}
+		for _, id := range ids {
+			if kind == KindGeneral && refs[id] {
+				continue
+			}
+			cfg, err := loadConfig(kind, id)
 			if err != nil { continue }
-			var cfg Config
-			if err := yaml.Unmarshal(b, &cfg); err != nil { continue }
-			configs = append(configs, cfg)
+			configs = append(configs, ps.annotate(cfg))
 		}
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -182,20 +250,24 @@ func handleGetAllConfigs(w http.ResponseWriter, r *http.Request) {
 func handleGetConfig(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
-	b, err := ioutil.ReadFile(configPath(id))
-	if err != nil {
+	cfg, err := loadConfig(KindGeneral, id)
+	if err == ErrConfigNotFound {
 		w.WriteHeader(http.StatusNotFound)
 		w.Write([]byte(`{"error": "Config bulunamadı"}`))
 		return
 	}
-	var cfg Config
-	if err := yaml.Unmarshal(b, &cfg); err != nil {
+	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Write([]byte(`{"error": "YAML parse hatası"}`))
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(cfg)
+	body, err := renderValue(cfg)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error": "JSON'a çevirilemedi"}`))
+		return
+	}
+	writeRendered(w, r, body)
 }
 
 // POST /api/configuration
@@ -208,37 +280,39 @@ func handlePostConfig(w http.ResponseWriter, r *http.Request) {
 	}
 	id, ok := cfg["id"].(string)
 	if !ok || id == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte(`{"error": "id alanı zorunlu"}`))
+		writeFieldError(w, "id", codeRequired, "zorunlu alan")
 		return
 	}
-	// actions validasyonu
-	actions, ok := cfg["actions"].([]interface{})
-	if !ok {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte(`{"error": "actions array zorunlu"}`))
+	if err := validateConfigID(KindGeneral, id); err != nil {
+		writeInvalidID(w, err)
 		return
 	}
-	valid, sanitized := validateAndSanitizeActions(actions)
-	if !valid {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte(`{"error": "actions geçersiz veya tehlikeli içerik"}`))
+	if !validateBody(w, KindGeneral, cfg) || !validateBodyExtends(w, KindGeneral, cfg) {
+		return
+	}
+	// actions validasyonu
+	report, err := prepareConfigActions(cfg, strictSanitize(r))
+	if err != nil {
+		if !writeValidationError(w, err) {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error": "Sanitizer politikası okunamadı"}`))
+		}
 		return
 	}
-	cfg["actions"] = sanitized
 	b, err := yaml.Marshal(cfg)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Write([]byte(`{"error": "YAML'e çevirilemedi"}`))
 		return
 	}
-	if err := ioutil.WriteFile(configPath(id), b, 0644); err != nil {
+	defer lockConfig(KindGeneral, id)()
+	if err := writeConfig(KindGeneral, id, b, requestAuthor(r)); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Write([]byte(`{"error": "Dosya yazılamadı"}`))
 		return
 	}
-	w.WriteHeader(http.StatusCreated)
-	w.Write([]byte(`{"message": "Config eklendi"}`))
+	setStoredETag(w, KindGeneral, id)
+	writeSaveResult(w, http.StatusCreated, "Config eklendi", report)
 }
 
 // PUT /api/configuration/{id}
@@ -251,41 +325,59 @@ func handlePutConfig(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte(`{"error": "JSON parse hatası"}`))
 		return
 	}
-	// actions validasyonu
-	actions, ok := cfg["actions"].([]interface{})
-	if !ok {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte(`{"error": "actions array zorunlu"}`))
+	if _, ok := reconcileBodyID(w, cfg["id"], id); !ok {
 		return
 	}
-	valid, sanitized := validateAndSanitizeActions(actions)
-	if !valid {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte(`{"error": "actions geçersiz veya tehlikeli içerik"}`))
+	cfg["id"] = id
+	if !validateBody(w, KindGeneral, cfg) || !validateBodyExtends(w, KindGeneral, cfg) {
+		return
+	}
+	// actions validasyonu
+	report, err := prepareConfigActions(cfg, strictSanitize(r))
+	if err != nil {
+		if !writeValidationError(w, err) {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error": "Sanitizer politikası okunamadı"}`))
+		}
 		return
 	}
-	cfg["actions"] = sanitized
 	b, err := yaml.Marshal(cfg)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Write([]byte(`{"error": "YAML'e çevirilemedi"}`))
 		return
 	}
-	if err := ioutil.WriteFile(configPath(id), b, 0644); err != nil {
+	defer lockConfig(KindGeneral, id)()
+	if !checkIfMatch(w, r, KindGeneral, id) {
+		return
+	}
+	created, ok := checkPutTarget(w, r, KindGeneral, id)
+	if !ok {
+		return
+	}
+	if err := writeConfig(KindGeneral, id, b, requestAuthor(r)); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Write([]byte(`{"error": "Dosya yazılamadı"}`))
 		return
 	}
-	w.Write([]byte(`{"message": "Config güncellendi"}`))
+	setStoredETag(w, KindGeneral, id)
+	if created {
+		writeSaveResult(w, http.StatusCreated, "Config eklendi", report)
+		return
+	}
+	writeSaveResult(w, http.StatusOK, "Config güncellendi", report)
 }
 
 // DELETE /api/configuration/{id}
 func handleDeleteConfig(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
-	if err := os.Remove(configPath(id)); err != nil {
-		w.WriteHeader(http.StatusNotFound)
-		w.Write([]byte(`{"error": "Config silinemedi"}`))
+	defer lockConfig(KindGeneral, id)()
+	if !checkIfMatch(w, r, KindGeneral, id) {
+		return
+	}
+	if err := deleteConfig(KindGeneral, id, requestAuthor(r)); err != nil {
+		writeDeleteError(w, err, "Config silinemedi")
 		return
 	}
 	w.Write([]byte(`{"message": "Config silindi"}`))
@@ -299,47 +391,70 @@ func handleGetSpecificConfig(w http.ResponseWriter, r *http.Request) {
 
 	// 1. id ile arama
 	if id != "" {
-		b, err := ioutil.ReadFile(specificConfigPath(id))
-		if err == nil {
-			var cfg Config
-			if err := yaml.Unmarshal(b, &cfg); err == nil {
-				w.Header().Set("Content-Type", "application/json")
-				json.NewEncoder(w).Encode(cfg)
+		if err := validateConfigID(KindSpecific, id); err != nil {
+			writeInvalidID(w, err)
+			return
+		}
+		if cfg, err := loadConfig(KindSpecific, id); err == nil {
+			if cfg, err = flattenConfig(KindSpecific, cfg); err != nil {
+				if !writeValidationError(w, err) {
+					w.WriteHeader(http.StatusInternalServerError)
+					w.Write([]byte(`{"error": "Üst config okunamadı"}`))
+				}
 				return
 			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(withAppliedPolicy(cfg))
+			return
 		}
 	}
 
 	// 2. host/url ile arama (tüm spesifikleri tara)
-	files, err := ioutil.ReadDir(configDir)
+	ids, err := store.List(KindSpecific)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Write([]byte(`{"error": "Config klasörü okunamadı"}`))
 		return
 	}
-	for _, file := range files {
-		if filepath.Ext(file.Name()) == ".yaml" && filepath.HasPrefix(file.Name(), "specific_") {
-			b, err := ioutil.ReadFile(filepath.Join(configDir, file.Name()))
-			if err != nil { continue }
-			var cfg Config
-			if err := yaml.Unmarshal(b, &cfg); err != nil { continue }
-			if ds, ok := cfg["datasource"].(map[string]interface{}); ok {
-				if hosts, ok := ds["hosts"].(map[string]interface{}); ok {
-					if _, ok := hosts[host]; ok {
-						w.Header().Set("Content-Type", "application/json")
-						json.NewEncoder(w).Encode(cfg)
-						return
-					}
-				}
-				if urls, ok := ds["urls"].(map[string]interface{}); ok {
-					if _, ok := urls[url]; ok {
-						w.Header().Set("Content-Type", "application/json")
-						json.NewEncoder(w).Encode(cfg)
-						return
-					}
-				}
+	var best *hostPattern
+	var bestCfg, urlCfg Config
+	// Zinciri bozuk configler kendi datasource'larıyla değerlendirilir; kazanırlarsa hata döner
+	broken := map[string]error{}
+	for _, sid := range ids {
+		cfg, err := loadConfig(KindSpecific, sid)
+		if err != nil { continue }
+		// Devralınan hostlar/url'ler de eşleşir
+		if flat, err := flattenConfig(KindSpecific, cfg); err != nil {
+			broken[sid] = err
+		} else {
+			cfg = flat
+		}
+		ds := asMap(cfg["datasource"])
+		if host != "" {
+			if p := bestHostPattern(asMap(ds["hosts"]), host); p != nil && (best == nil || p.specificity(best) > 0) {
+				best, bestCfg = p, cfg
 			}
 		}
+		if _, ok := asMap(ds["urls"])[url]; ok && url != "" && urlCfg == nil {
+			urlCfg = cfg
+		}
+	}
+	// Host eşleşmesi url'den önce gelir
+	match := urlCfg
+	if best != nil {
+		match = bestCfg
+	}
+	if match != nil {
+		if sid, _ := match["id"].(string); broken[sid] != nil {
+			if !writeValidationError(w, broken[sid]) {
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(`{"error": "Üst config okunamadı"}`))
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(withAppliedPolicy(match))
+		return
 	}
 	w.WriteHeader(http.StatusNotFound)
 	w.Write([]byte(`{"error": "Spesifik config bulunamadı"}`))
@@ -349,20 +464,24 @@ func handleGetSpecificConfig(w http.ResponseWriter, r *http.Request) {
 func handleGetSpecificById(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
-	b, err := ioutil.ReadFile(specificConfigPath(id))
-	if err != nil {
+	cfg, err := loadConfig(KindSpecific, id)
+	if err == ErrConfigNotFound {
 		w.WriteHeader(http.StatusNotFound)
 		w.Write([]byte(`{"error": "Spesifik config bulunamadı"}`))
 		return
 	}
-	var cfg Config
-	if err := yaml.Unmarshal(b, &cfg); err != nil {
+	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Write([]byte(`{"error": "YAML parse hatası"}`))
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(cfg)
+	body, err := renderValue(cfg)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error": "JSON'a çevirilemedi"}`))
+		return
+	}
+	writeRendered(w, r, body)
 }
 
 // POST /api/specific
@@ -375,37 +494,39 @@ func handlePostSpecific(w http.ResponseWriter, r *http.Request) {
 	}
 	id, ok := cfg["id"].(string)
 	if !ok || id == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte(`{"error": "id alanı zorunlu"}`))
+		writeFieldError(w, "id", codeRequired, "zorunlu alan")
 		return
 	}
-	// actions validasyonu
-	actions, ok := cfg["actions"].([]interface{})
-	if !ok {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte(`{"error": "actions array zorunlu"}`))
+	if err := validateConfigID(KindSpecific, id); err != nil {
+		writeInvalidID(w, err)
 		return
 	}
-	valid, sanitized := validateAndSanitizeActions(actions)
-	if !valid {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte(`{"error": "actions geçersiz veya tehlikeli içerik"}`))
+	if !validateBody(w, KindSpecific, cfg) || !validateBodyExtends(w, KindSpecific, cfg) {
+		return
+	}
+	// actions validasyonu
+	report, err := prepareConfigActions(cfg, strictSanitize(r))
+	if err != nil {
+		if !writeValidationError(w, err) {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error": "Sanitizer politikası okunamadı"}`))
+		}
 		return
 	}
-	cfg["actions"] = sanitized
 	b, err := yaml.Marshal(cfg)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Write([]byte(`{"error": "YAML'e çevirilemedi"}`))
 		return
 	}
-	if err := ioutil.WriteFile(specificConfigPath(id), b, 0644); err != nil {
+	defer lockConfig(KindSpecific, id)()
+	if err := writeConfig(KindSpecific, id, b, requestAuthor(r)); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Write([]byte(`{"error": "Dosya yazılamadı"}`))
 		return
 	}
-	w.WriteHeader(http.StatusCreated)
-	w.Write([]byte(`{"message": "Spesifik config eklendi"}`))
+	setStoredETag(w, KindSpecific, id)
+	writeSaveResult(w, http.StatusCreated, "Spesifik config eklendi", report)
 }
 
 // PUT /api/specific/{id}
@@ -418,41 +539,59 @@ func handlePutSpecific(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte(`{"error": "JSON parse hatası"}`))
 		return
 	}
-	// actions validasyonu
-	actions, ok := cfg["actions"].([]interface{})
-	if !ok {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte(`{"error": "actions array zorunlu"}`))
+	if _, ok := reconcileBodyID(w, cfg["id"], id); !ok {
 		return
 	}
-	valid, sanitized := validateAndSanitizeActions(actions)
-	if !valid {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte(`{"error": "actions geçersiz veya tehlikeli içerik"}`))
+	cfg["id"] = id
+	if !validateBody(w, KindSpecific, cfg) || !validateBodyExtends(w, KindSpecific, cfg) {
+		return
+	}
+	// actions validasyonu
+	report, err := prepareConfigActions(cfg, strictSanitize(r))
+	if err != nil {
+		if !writeValidationError(w, err) {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error": "Sanitizer politikası okunamadı"}`))
+		}
 		return
 	}
-	cfg["actions"] = sanitized
 	b, err := yaml.Marshal(cfg)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Write([]byte(`{"error": "YAML'e çevirilemedi"}`))
 		return
 	}
-	if err := ioutil.WriteFile(specificConfigPath(id), b, 0644); err != nil {
+	defer lockConfig(KindSpecific, id)()
+	if !checkIfMatch(w, r, KindSpecific, id) {
+		return
+	}
+	created, ok := checkPutTarget(w, r, KindSpecific, id)
+	if !ok {
+		return
+	}
+	if err := writeConfig(KindSpecific, id, b, requestAuthor(r)); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Write([]byte(`{"error": "Dosya yazılamadı"}`))
 		return
 	}
-	w.Write([]byte(`{"message": "Spesifik config güncellendi"}`))
+	setStoredETag(w, KindSpecific, id)
+	if created {
+		writeSaveResult(w, http.StatusCreated, "Spesifik config eklendi", report)
+		return
+	}
+	writeSaveResult(w, http.StatusOK, "Spesifik config güncellendi", report)
 }
 
 // DELETE /api/specific/{id}
 func handleDeleteSpecific(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
-	if err := os.Remove(specificConfigPath(id)); err != nil {
-		w.WriteHeader(http.StatusNotFound)
-		w.Write([]byte(`{"error": "Spesifik config silinemedi"}`))
+	defer lockConfig(KindSpecific, id)()
+	if !checkIfMatch(w, r, KindSpecific, id) {
+		return
+	}
+	if err := deleteConfig(KindSpecific, id, requestAuthor(r)); err != nil {
+		writeDeleteError(w, err, "Spesifik config silinemedi")
 		return
 	}
 	w.Write([]byte(`{"message": "Spesifik config silindi"}`))
@@ -468,6 +607,10 @@ func handleGetAllPagesConfigs(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte(`{"error": "Pages config klasörü okunamadı"}`))
 		return
 	}
+	ps, _ := loadPolicySet()
+	for i := range configs {
+		ps.annotatePages(&configs[i])
+	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(configs)
 }
@@ -477,38 +620,61 @@ func handleGetPagesConfig(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 	cfg, err := loadPagesConfig(id)
-	if err != nil {
+	if err == ErrConfigNotFound {
 		w.WriteHeader(http.StatusNotFound)
 		w.Write([]byte(`{"error": "Pages config bulunamadı"}`))
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(cfg)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Pages config okunamadı: " + err.Error()})
+		return
+	}
+	body, err := renderValue(cfg)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error": "JSON'a çevirilemedi"}`))
+		return
+	}
+	writeRendered(w, r, body)
 }
 
 // POST /api/pages
 func handlePostPagesConfig(w http.ResponseWriter, r *http.Request) {
-	var cfg PagesConfig
-	if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		w.Write([]byte(`{"error": "JSON parse hatası"}`))
 		return
 	}
 	
-	if cfg.ID == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte(`{"error": "id alanı zorunlu"}`))
+	id, ok := body["id"].(string)
+	if !ok || id == "" {
+		writeFieldError(w, "id", codeRequired, "zorunlu alan")
+		return
+	}
+	if err := validateConfigID(KindPages, id); err != nil {
+		writeInvalidID(w, err)
+		return
+	}
+	cfg, ok := decodePagesBody(w, body)
+	if !ok {
 		return
 	}
 	
-	if err := savePagesConfig(&cfg); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte(fmt.Sprintf(`{"error": "%s"}`, err.Error())))
+	defer lockConfig(KindPages, cfg.ID)()
+	report, err := savePagesConfig(cfg, requestAuthor(r), strictSanitize(r))
+	if err != nil {
+		if writeValidationError(w, err) {
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error": "Dosya yazılamadı"}`))
 		return
 	}
 	
-	w.WriteHeader(http.StatusCreated)
-	w.Write([]byte(`{"message": "Pages config eklendi"}`))
+	setStoredETag(w, KindPages, cfg.ID)
+	writeSaveResult(w, http.StatusCreated, "Pages config eklendi", report)
 }
 
 // PUT /api/pages/{id}
@@ -516,31 +682,58 @@ func handlePutPagesConfig(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 	
-	var cfg PagesConfig
-	if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
+	var body map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		w.Write([]byte(`{"error": "JSON parse hatası"}`))
 		return
 	}
 	
-	cfg.ID = id // URL'den gelen ID'yi kullan
+	if _, ok := reconcileBodyID(w, body["id"], id); !ok {
+		return
+	}
+	body["id"] = id
+	cfg, ok := decodePagesBody(w, body)
+	if !ok {
+		return
+	}
 	
-	if err := savePagesConfig(&cfg); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte(fmt.Sprintf(`{"error": "%s"}`, err.Error())))
+	defer lockConfig(KindPages, id)()
+	if !checkIfMatch(w, r, KindPages, id) {
+		return
+	}
+	created, ok := checkPutTarget(w, r, KindPages, id)
+	if !ok {
+		return
+	}
+	report, err := savePagesConfig(cfg, requestAuthor(r), strictSanitize(r))
+	if err != nil {
+		if writeValidationError(w, err) {
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error": "Dosya yazılamadı"}`))
 		return
 	}
 	
-	w.Write([]byte(`{"message": "Pages config güncellendi"}`))
+	setStoredETag(w, KindPages, id)
+	if created {
+		writeSaveResult(w, http.StatusCreated, "Pages config eklendi", report)
+		return
+	}
+	writeSaveResult(w, http.StatusOK, "Pages config güncellendi", report)
 }
 
 // DELETE /api/pages/{id}
 func handleDeletePagesConfig(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
-	if err := os.Remove(pagesConfigPath(id)); err != nil {
-		w.WriteHeader(http.StatusNotFound)
-		w.Write([]byte(`{"error": "Pages config silinemedi"}`))
+	defer lockConfig(KindPages, id)()
+	if !checkIfMatch(w, r, KindPages, id) {
+		return
+	}
+	if err := deleteConfig(KindPages, id, requestAuthor(r)); err != nil {
+		writeDeleteError(w, err, "Pages config silinemedi")
 		return
 	}
 	w.Write([]byte(`{"message": "Pages config silindi"}`))
@@ -564,53 +757,71 @@ func handleResolvePagesConfig(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte(`{"error": "Pages config okunamadı"}`))
 		return
 	}
-	
-	// Öncelik sırası: page > url > host
-	for _, cfg := range configs {
-		// Page bazlı eşleşme
-		if page != "" && cfg.Datasource.Pages != nil {
-			if configRef, exists := cfg.Datasource.Pages[page]; exists {
-				result := map[string]interface{}{
-					"config": cfg,
-					"matched_by": "page",
-					"matched_value": page,
-					"config_ref": configRef,
-				}
-				w.Header().Set("Content-Type", "application/json")
-				json.NewEncoder(w).Encode(result)
+	// extends zinciri birleştirilerek değerlendirilir. Zinciri bozuk configler kendi
+	// datasource'larıyla yarışır; kazanırlarsa zincir hatası sorun olarak raporlanır.
+	flat := configs[:0]
+	for i := range configs {
+		cfg, err := flattenPagesConfig(&configs[i])
+		if err != nil {
+			verrs, ok := asValidationErrors(err)
+			if !ok {
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(`{"error": "Üst config okunamadı"}`))
 				return
 			}
+			cfg = &configs[i]
+			cfg.Problems = append(append(ValidationErrors{}, cfg.Problems...), verrs...)
 		}
-		
-		// URL bazlı eşleşme
-		if url != "" && cfg.Datasource.URLs != nil {
-			if configRef, exists := cfg.Datasource.URLs[url]; exists {
-				result := map[string]interface{}{
-					"config": cfg,
-					"matched_by": "url",
-					"matched_value": url,
-					"config_ref": configRef,
-				}
-				w.Header().Set("Content-Type", "application/json")
-				json.NewEncoder(w).Encode(result)
-				return
-			}
+		flat = append(flat, *cfg)
+	}
+	configs = flat
+	
+	// Tüm configler değerlendirilir: eşleşme türü (page > url > host), özgüllük, priority, id
+	candidates := rankResolveCandidates(configs, page, url, host)
+	if len(candidates) > 0 {
+		winner := candidates[0]
+		// Kayıtlı hali güncel kurallara uymayan config sessizce atlanmaz, sorunları raporlanır
+		if len(winner.Config.Problems) > 0 {
+			writeValidationErrors(w, winner.Config.Problems)
+			return
 		}
-		
-		// Host bazlı eşleşme
-		if host != "" && cfg.Datasource.Hosts != nil {
-			if configRef, exists := cfg.Datasource.Hosts[host]; exists {
-				result := map[string]interface{}{
-					"config": cfg,
-					"matched_by": "host",
-					"matched_value": host,
-					"config_ref": configRef,
-				}
-				w.Header().Set("Content-Type", "application/json")
-				json.NewEncoder(w).Encode(result)
+		// Referans verilen dosyaların aksiyonları config'in kendi aksiyonlarının ardına eklenir
+		sanitizer, err := currentSanitizer(winner.Config.SanitizerPolicy, winner.Config.hosts())
+		var refActions Actions
+		var refs []string
+		if err == nil {
+			// Kazananın kendi ve devraldığı aksiyonlar da uygulanan politikayla temizlenir
+			_, err = validateAndSanitizeActions(winner.Config.Actions, sanitizer, false)
+		}
+		if err == nil {
+			refActions, refs, err = loadRefActions(winner.ConfigRef, winner.refPath(), sanitizer)
+		}
+		if err != nil {
+			if writeValidationError(w, err) {
 				return
 			}
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error": "Referans verilen config okunamadı"}`))
+			return
 		}
+		effective := append(append(Actions{}, winner.Config.Actions...), refActions...)
+		winner.Config.AppliedSanitizerPolicy = sanitizer.policy.ID
+		result := map[string]interface{}{
+			"config": winner.Config,
+			"matched_by": winner.MatchedBy,
+			"matched_value": winner.MatchedValue,
+			"config_ref": winner.ConfigRef,
+			"loaded_refs": refs,
+			"effective_actions": effective,
+			"candidates": candidates,
+			"explanation": explainResolve(candidates),
+		}
+		if winner.MatchedPattern != "" {
+			result["matched_pattern"] = winner.MatchedPattern
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+		return
 	}
 	
 	w.WriteHeader(http.StatusNotFound)
@@ -637,7 +848,8 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
-func main() {
+// Tüm API route'larını içeren router
+func newRouter() *mux.Router {
 	router := mux.NewRouter()
 
 	// Test endpoint
@@ -648,30 +860,108 @@ func main() {
 	})
 
 	router.HandleFunc("/api/configuration/all", handleGetAllConfigs).Methods("GET")
-	router.HandleFunc("/api/configuration/{id}", handleGetConfig).Methods("GET")
+	router.HandleFunc("/api/configuration/{id}", withValidID(KindGeneral, handleGetConfig)).Methods("GET")
 	router.HandleFunc("/api/configuration", handlePostConfig).Methods("POST")
-	router.HandleFunc("/api/configuration/{id}", handlePutConfig).Methods("PUT")
-	router.HandleFunc("/api/configuration/{id}", handleDeleteConfig).Methods("DELETE")
+	router.HandleFunc("/api/configuration/{id}", withValidID(KindGeneral, handlePutConfig)).Methods("PUT")
+	router.HandleFunc("/api/configuration/{id}", withValidID(KindGeneral, handleDeleteConfig)).Methods("DELETE")
+	router.HandleFunc("/api/configuration/{id}/history", withValidID(KindGeneral, handleListHistory(KindGeneral))).Methods("GET")
+	router.HandleFunc("/api/configuration/{id}/history/{rev}", withValidID(KindGeneral, handleGetRevision(KindGeneral))).Methods("GET")
+	router.HandleFunc("/api/configuration/{id}/history/{rev}/rollback", withValidID(KindGeneral, handleRollback(KindGeneral))).Methods("POST")
+	router.HandleFunc("/api/configuration/{id}/diff", withValidID(KindGeneral, handleDiffRevisions(KindGeneral))).Methods("GET")
+	router.HandleFunc("/api/configuration/{id}/flattened", withValidID(KindGeneral, handleGetFlattened(KindGeneral))).Methods("GET")
 
 	router.HandleFunc("/api/specific", handleGetSpecificConfig).Methods("GET")
-	router.HandleFunc("/api/specific/{id}", handleGetSpecificById).Methods("GET")
+	router.HandleFunc("/api/specific/{id}", withValidID(KindSpecific, handleGetSpecificById)).Methods("GET")
 	router.HandleFunc("/api/specific", handlePostSpecific).Methods("POST")
-	router.HandleFunc("/api/specific/{id}", handlePutSpecific).Methods("PUT")
-	router.HandleFunc("/api/specific/{id}", handleDeleteSpecific).Methods("DELETE")
+	router.HandleFunc("/api/specific/{id}", withValidID(KindSpecific, handlePutSpecific)).Methods("PUT")
+	router.HandleFunc("/api/specific/{id}", withValidID(KindSpecific, handleDeleteSpecific)).Methods("DELETE")
+	router.HandleFunc("/api/specific/{id}/history", withValidID(KindSpecific, handleListHistory(KindSpecific))).Methods("GET")
+	router.HandleFunc("/api/specific/{id}/history/{rev}", withValidID(KindSpecific, handleGetRevision(KindSpecific))).Methods("GET")
+	router.HandleFunc("/api/specific/{id}/history/{rev}/rollback", withValidID(KindSpecific, handleRollback(KindSpecific))).Methods("POST")
+	router.HandleFunc("/api/specific/{id}/diff", withValidID(KindSpecific, handleDiffRevisions(KindSpecific))).Methods("GET")
+	router.HandleFunc("/api/specific/{id}/flattened", withValidID(KindSpecific, handleGetFlattened(KindSpecific))).Methods("GET")
 
 	// Pages Configuration Routes
 	router.HandleFunc("/api/pages/all", handleGetAllPagesConfigs).Methods("GET")
 	router.HandleFunc("/api/pages/resolve", handleResolvePagesConfig).Methods("GET")
-	router.HandleFunc("/api/pages/{id}", handleGetPagesConfig).Methods("GET")
+	router.HandleFunc("/api/pages/{id}", withValidID(KindPages, handleGetPagesConfig)).Methods("GET")
 	router.HandleFunc("/api/pages", handlePostPagesConfig).Methods("POST")
-	router.HandleFunc("/api/pages/{id}", handlePutPagesConfig).Methods("PUT")
-	router.HandleFunc("/api/pages/{id}", handleDeletePagesConfig).Methods("DELETE")
+	router.HandleFunc("/api/pages/{id}", withValidID(KindPages, handlePutPagesConfig)).Methods("PUT")
+	router.HandleFunc("/api/pages/{id}", withValidID(KindPages, handleDeletePagesConfig)).Methods("DELETE")
+	router.HandleFunc("/api/pages/{id}/history", withValidID(KindPages, handleListHistory(KindPages))).Methods("GET")
+	router.HandleFunc("/api/pages/{id}/history/{rev}", withValidID(KindPages, handleGetRevision(KindPages))).Methods("GET")
+	router.HandleFunc("/api/pages/{id}/history/{rev}/rollback", withValidID(KindPages, handleRollback(KindPages))).Methods("POST")
+	router.HandleFunc("/api/pages/{id}/diff", withValidID(KindPages, handleDiffRevisions(KindPages))).Methods("GET")
+	router.HandleFunc("/api/pages/{id}/flattened", withValidID(KindPages, handleGetFlattened(KindPages))).Methods("GET")
+
+	// Sanitizer politikaları
+	router.HandleFunc("/api/policies", handleGetAllPolicies).Methods("GET")
+	router.HandleFunc("/api/policies/{id}", withValidID(KindPolicy, handleGetPolicy)).Methods("GET")
+	router.HandleFunc("/api/policies", handlePostPolicy).Methods("POST")
+	router.HandleFunc("/api/policies/{id}", withValidID(KindPolicy, handlePutPolicy)).Methods("PUT")
+	router.HandleFunc("/api/policies/{id}", withValidID(KindPolicy, handleDeletePolicy)).Methods("DELETE")
+
+	// JSON şemaları
+	router.HandleFunc("/api/schema/{kind}", handleGetSchema).Methods("GET")
+
+	// Çöp kutusu
+	router.HandleFunc("/api/trash", handleListTrash).Methods("GET")
+	router.HandleFunc("/api/trash/{kind}/{id}/restore", handleRestoreTrash).Methods("POST")
+	router.HandleFunc("/api/trash/{kind}/{id}", handlePurgeTrash).Methods("DELETE")
+
+	return router
+}
+
+func main() {
+	storeKind := flag.String("store", "file", "config store: file veya sqlite")
+	sqlitePath := flag.String("sqlite-path", "visionbridge.db", "sqlite store veritabanı dosyası")
+	importDir := flag.String("import", "", "başlangıçta bu klasördeki YAML configleri store'a aktar")
+	trashRetentionFlag := flag.Duration("trash-retention", trashRetention, "silinen configlerin çöp kutusunda tutulma süresi")
+	cacheInterval := flag.Duration("cache-interval", 2*time.Second, "config cache'inin store değişikliklerini kontrol etme aralığı (0: cache kapalı)")
+	flag.Parse()
+	trashRetention = *trashRetentionFlag
+
+	switch *storeKind {
+	case "file":
+		store = NewFileStore(configDir)
+	case "sqlite":
+		s, err := OpenSQLiteStore(*sqlitePath)
+		if err != nil {
+			log.Fatalf("SQLite store açılamadı: %v", err)
+		}
+		defer s.Close()
+		store = s
+	default:
+		log.Fatalf("Bilinmeyen store: %s", *storeKind)
+	}
+	if *importDir != "" {
+		n, err := importConfigs(store, NewFileStore(*importDir))
+		if err != nil {
+			log.Fatalf("Config aktarımı başarısız: %v", err)
+		}
+		fmt.Printf("%d config aktarıldı (%s)\n", n, *importDir)
+	}
+
+	if *cacheInterval > 0 {
+		cached, err := NewCachedStore(store)
+		if err != nil {
+			log.Fatalf("Config cache yüklenemedi: %v", err)
+		}
+		cached.Watch(*cacheInterval)
+		defer cached.Close()
+		store = cached.Store()
+	}
+
+	startTrashPurger(time.Hour)
+
+	router := newRouter()
 
 	// CORS ayarları
 	c := cors.New(cors.Options{
 		AllowedOrigins:   []string{"*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"*"},
+		ExposedHeaders:   []string{"ETag"},
 		AllowCredentials: true,
 	})
 
@@ -36,19 +36,22 @@ func TestIsValidSelector(t *testing.T) {
 }
 
 func TestValidateAndSanitizeActions(t *testing.T) {
-	actions := []interface{}{
-		map[string]interface{}{"type": "replace", "selector": "#id", "newElement": `<div onclick="alert(1)">x</div>`},
-		map[string]interface{}{"type": "remove", "selector": ".class"},
+	actions := Actions{
+		&ReplaceAction{Type: "replace", Selector: "#id", NewElement: `<div onclick="alert(1)">x</div>`},
+		&RemoveAction{Type: "remove", Selector: ".class"},
 	}
-	valid, sanitized := validateAndSanitizeActions(actions)
-	if !valid {
-		t.Fatal("actions should be valid")
+	if _, err := validateAndSanitizeActions(actions, defaultSanitizer, false); err != nil {
+		t.Fatalf("actions should be valid: %v", err)
 	}
-	if html, ok := sanitized[0].(map[string]interface{})["newElement"].(string); ok {
-		t.Log("Sanitized HTML:", html)
-		if strings.Contains(html, "onclick") {
-			t.Error("sanitize failed in actions")
-		}
+	html := actions[0].(*ReplaceAction).NewElement
+	t.Log("Sanitized HTML:", html)
+	if strings.Contains(html, "onclick") {
+		t.Error("sanitize failed in actions")
+	}
+
+	invalid := Actions{&RemoveAction{Type: "remove", Selector: "<script>"}}
+	if _, err := validateAndSanitizeActions(invalid, defaultSanitizer, false); err == nil {
+		t.Error("invalid selector should be rejected")
 	}
 }
 
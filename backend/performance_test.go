@@ -14,6 +14,7 @@ import (
 
 // Backend Response Time Test - <200ms hedefi
 func TestBackendResponseTime(t *testing.T) {
+	useTempConfigDir(t, "demo.yaml")
 	// Test server'ı oluştur
 	router := mux.NewRouter()
 	router.HandleFunc("/api/configuration/all", handleGetAllConfigs).Methods("GET")
@@ -144,6 +145,7 @@ func TestBackendResponseTime(t *testing.T) {
 
 // Benchmark testleri
 func BenchmarkGetAllConfigs(b *testing.B) {
+	useTempConfigDir(b, "demo.yaml")
 	router := mux.NewRouter()
 	router.HandleFunc("/api/configuration/all", handleGetAllConfigs).Methods("GET")
 	
@@ -157,6 +159,7 @@ func BenchmarkGetAllConfigs(b *testing.B) {
 }
 
 func BenchmarkGetSingleConfig(b *testing.B) {
+	useTempConfigDir(b, "demo.yaml")
 	router := mux.NewRouter()
 	router.HandleFunc("/api/configuration/{id}", handleGetConfig).Methods("GET")
 	
@@ -170,6 +173,7 @@ func BenchmarkGetSingleConfig(b *testing.B) {
 }
 
 func BenchmarkPostConfig(b *testing.B) {
+	useTempConfigDir(b)
 	router := mux.NewRouter()
 	router.HandleFunc("/api/configuration", handlePostConfig).Methods("POST")
 	
@@ -196,6 +200,7 @@ func BenchmarkPostConfig(b *testing.B) {
 
 // Load test simülasyonu
 func TestLoadSimulation(t *testing.T) {
+	useTempConfigDir(t, "demo.yaml")
 	router := mux.NewRouter()
 	router.HandleFunc("/api/configuration/all", handleGetAllConfigs).Methods("GET")
 	
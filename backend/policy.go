@@ -0,0 +1,438 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"gopkg.in/yaml.v3"
+)
+
+// Hiçbir politika seçilmediğinde kullanılan politika; store'a bu id ile kaydedilirse yerleşik olanın yerini alır
+const defaultPolicyID = "default"
+
+// Politika ile açılması güvensiz URL şemaları
+var unsafeURLSchemes = map[string]bool{"javascript": true, "vbscript": true, "data": true, "file": true}
+
+// YAML içeriğini SanitizerPolicy olarak parse et
+func parseSanitizerPolicy(b []byte) (*SanitizerPolicy, error) {
+	var p SanitizerPolicy
+	if err := yaml.Unmarshal(b, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Politikayı store'dan yükle; kayıtlı "default" yoksa yerleşik varsayılan döner
+func loadSanitizerPolicy(id string) (*SanitizerPolicy, error) {
+	b, err := store.Get(KindPolicy, id)
+	if err == ErrConfigNotFound && id == defaultPolicyID {
+		p := DefaultSanitizerPolicy
+		return &p, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseSanitizerPolicy(b)
+}
+
+// Kayıtlı tüm politikalar (yerleşik varsayılan dahil), id sırasıyla
+func getAllSanitizerPolicies() ([]SanitizerPolicy, error) {
+	ids, err := store.List(KindPolicy)
+	if err != nil {
+		return nil, err
+	}
+	var policies []SanitizerPolicy
+	hasDefault := false
+	for _, id := range ids {
+		p, err := loadSanitizerPolicy(id)
+		if err != nil {
+			continue
+		}
+		hasDefault = hasDefault || id == defaultPolicyID
+		policies = append(policies, *p)
+	}
+	if !hasDefault {
+		policies = append(policies, DefaultSanitizerPolicy)
+	}
+	sort.Slice(policies, func(i, j int) bool { return policies[i].ID < policies[j].ID })
+	return policies, nil
+}
+
+// policySet: bir istekte birden çok config için politika seçerken store'a bir kez gidilsin diye
+// yüklenmiş politikalar (yerleşik varsayılan dahil)
+type policySet struct {
+	policies   []SanitizerPolicy
+	sanitizers map[string]*Sanitizer // politika id -> sanitizer, istek boyunca yeniden kullanılır
+}
+
+func loadPolicySet() (*policySet, error) {
+	policies, err := getAllSanitizerPolicies()
+	if err != nil {
+		return nil, err
+	}
+	return &policySet{policies: policies}, nil
+}
+
+func (ps *policySet) find(id string) (SanitizerPolicy, bool) {
+	for _, p := range ps.policies {
+		if p.ID == id {
+			return p, true
+		}
+	}
+	return SanitizerPolicy{}, false
+}
+
+// Config'e uygulanacak sanitizer'ı seç: config'teki sanitizer_policy > datasource host eşleşmesi > varsayılan
+func resolveSanitizer(explicit string, hosts []string) (*Sanitizer, error) {
+	ps, err := loadPolicySet()
+	if err != nil {
+		return nil, err
+	}
+	p, err := ps.resolve(explicit, hosts)
+	if err != nil {
+		return nil, err
+	}
+	return NewSanitizer(p), nil
+}
+
+func (ps *policySet) resolve(explicit string, hosts []string) (SanitizerPolicy, error) {
+	if explicit != "" {
+		p, ok := ps.find(explicit)
+		if !ok {
+			return p, ValidationErrors{{Path: "sanitizer_policy", Code: codeUnknownPolicy, Message: "tanımlı olmayan politika: " + explicit}}
+		}
+		return p, nil
+	}
+	if len(hosts) > 0 {
+		var matched []SanitizerPolicy
+		for _, p := range ps.policies {
+			if policyCoversHosts(p, hosts) {
+				matched = append(matched, p)
+			}
+		}
+		if len(matched) > 1 {
+			ids := make([]string, len(matched))
+			for i, p := range matched {
+				ids[i] = p.ID
+			}
+			return SanitizerPolicy{}, ValidationErrors{{Path: "sanitizer_policy", Code: codeAmbiguousPolicy,
+				Message: "datasource hostları birden fazla politikaya bağlı (" + strings.Join(ids, ", ") + "); sanitizer_policy ile seçin"}}
+		}
+		if len(matched) == 1 {
+			return matched[0], nil
+		}
+	}
+	p, _ := ps.find(defaultPolicyID)
+	return p, nil
+}
+
+// Okuma sırasında config'e uygulanan politika. resolve ile aynı seçim yapılır;
+// açıkça seçilen politika kayıttan sonra silinmişse host eşleşmesine/varsayılana düşülür.
+func (ps *policySet) current(explicit string, hosts []string) (SanitizerPolicy, error) {
+	if _, ok := ps.find(explicit); !ok {
+		explicit = ""
+	}
+	return ps.resolve(explicit, hosts)
+}
+
+func currentSanitizer(explicit string, hosts []string) (*Sanitizer, error) {
+	ps, err := loadPolicySet()
+	if err != nil {
+		return nil, err
+	}
+	p, err := ps.current(explicit, hosts)
+	if err != nil {
+		return nil, err
+	}
+	return NewSanitizer(p), nil
+}
+
+func (ps *policySet) sanitizer(p SanitizerPolicy) *Sanitizer {
+	if s, ok := ps.sanitizers[p.ID]; ok {
+		return s
+	}
+	if ps.sanitizers == nil {
+		ps.sanitizers = map[string]*Sanitizer{}
+	}
+	s := NewSanitizer(p)
+	ps.sanitizers[p.ID] = s
+	return s
+}
+
+// GET yanıtları için uygulanan politika; seçilemiyorsa (ör. belirsiz host eşleşmesi) nil
+func (ps *policySet) applied(explicit string, hosts []string) *Sanitizer {
+	if ps == nil {
+		return nil
+	}
+	p, err := ps.current(explicit, hosts)
+	if err != nil {
+		return nil
+	}
+	return ps.sanitizer(p)
+}
+
+// Genel/spesifik config'e applied_sanitizer_policy alanını ekler (saklanan değer varsa yok sayılır)
+// ve HTML içerikleri o politikayla yeniden temizler. İçerik kayıt anındaki politikayla
+// temizlenmiştir; politika sonradan değişmiş, silinmiş ya da başka hosta bağlanmış olabilir.
+// Böylece yanıtta bildirilen politika yanıttaki içeriğe gerçekten uygulanmış olur.
+func (ps *policySet) annotate(cfg Config) Config {
+	explicit, _ := cfg["sanitizer_policy"].(string)
+	delete(cfg, "applied_sanitizer_policy")
+	if s := ps.applied(explicit, configHosts(cfg)); s != nil {
+		actions, _ := cfg["actions"].([]interface{})
+		for _, a := range actions {
+			act := asMap(a)
+			field := map[string]string{"replace": "newElement", "insert": "element"}[fmt.Sprint(act["type"])]
+			if html, ok := act[field].(string); ok {
+				act[field] = s.Sanitize(html)
+			}
+		}
+		cfg["applied_sanitizer_policy"] = s.policy.ID
+	}
+	return cfg
+}
+
+// Tek config yanıtları için annotate; politikalar okunamazsa alan eklenmez
+func withAppliedPolicy(cfg Config) Config {
+	ps, _ := loadPolicySet()
+	return ps.annotate(cfg)
+}
+
+// Pages config'in datasource.hosts anahtarları
+func (c *PagesConfig) hosts() []string {
+	out := make([]string, 0, len(c.Datasource.Hosts))
+	for h := range c.Datasource.Hosts {
+		out = append(out, h)
+	}
+	return out
+}
+
+// Pages config için annotate
+func (ps *policySet) annotatePages(c *PagesConfig) *PagesConfig {
+	c.AppliedSanitizerPolicy = ""
+	if s := ps.applied(c.SanitizerPolicy, c.hosts()); s != nil {
+		for _, act := range c.Actions {
+			switch a := act.(type) {
+			case *ReplaceAction:
+				a.NewElement = s.Sanitize(a.NewElement)
+			case *InsertAction:
+				a.Element = s.Sanitize(a.Element)
+			}
+		}
+		c.AppliedSanitizerPolicy = s.policy.ID
+	}
+	return c
+}
+
+// Politika hostu config hostunu kapsıyorsa (joker dahil, bkz. hostPattern) ya da ikisi aynı desense eşleşir
+func policyCoversHosts(p SanitizerPolicy, hosts []string) bool {
+	for _, ph := range p.Hosts {
+		pattern := parseHostPattern(ph)
+		for _, h := range hosts {
+			if h := normalizeHost(h); pattern.match(h) || normalizeHost(ph) == h {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Genel/spesifik config'in datasource.hosts anahtarları
+func configHosts(cfg Config) []string {
+	hosts := asMap(asMap(cfg["datasource"])["hosts"])
+	out := make([]string, 0, len(hosts))
+	for h := range hosts {
+		out = append(out, h)
+	}
+	return out
+}
+
+// Genel/spesifik config için sanitizer'ı seç
+func configSanitizer(cfg Config) (*Sanitizer, error) {
+	explicit, ok := cfg["sanitizer_policy"].(string)
+	if !ok && cfg["sanitizer_policy"] != nil {
+		return nil, ValidationErrors{{Path: "sanitizer_policy", Code: codeInvalidType, Message: "string olmalı"}}
+	}
+	return resolveSanitizer(explicit, configHosts(cfg))
+}
+
+// Politikanın güvenlik sınırlarını aşmadığını denetle
+func validateSanitizerPolicy(p *SanitizerPolicy) error {
+	var errs ValidationErrors
+	checkAttrs := func(path string, attrs []string) {
+		for i, a := range attrs {
+			a = strings.ToLower(a)
+			if strings.HasPrefix(a, "on") || a == "style" || a == "srcdoc" {
+				errs = append(errs, ValidationError{Path: fmt.Sprintf("%s[%d]", path, i), Code: codeForbiddenValue, Message: "bu attribute politikayla açılamaz"})
+			}
+		}
+	}
+	names := make([]string, 0, len(p.Elements))
+	for name := range p.Elements {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if forbiddenElements[strings.ToLower(name)] {
+			errs = append(errs, ValidationError{Path: "elements." + name, Code: codeForbiddenValue, Message: "bu element politikayla açılamaz"})
+			continue
+		}
+		checkAttrs("elements."+name, p.Elements[name])
+	}
+	checkAttrs("global_attributes", p.GlobalAttributes)
+	for i, scheme := range p.URLSchemes {
+		if unsafeURLSchemes[strings.ToLower(scheme)] {
+			errs = append(errs, ValidationError{Path: fmt.Sprintf("url_schemes[%d]", i), Code: codeForbiddenValue, Message: "güvensiz URL şeması"})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Politikayı doğrula ve kaydet
+func saveSanitizerPolicy(p *SanitizerPolicy, author string) error {
+	if err := validateSanitizerPolicy(p); err != nil {
+		return err
+	}
+	b, err := yaml.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return writeConfig(KindPolicy, p.ID, b, author)
+}
+
+// Politika gövdesini oku; bilinmeyen alanlar (yazım hataları) kabul edilmez
+func decodeSanitizerPolicy(w http.ResponseWriter, r *http.Request) (*SanitizerPolicy, bool) {
+	var p SanitizerPolicy
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": "JSON parse hatası"}`))
+		return nil, false
+	}
+	return &p, true
+}
+
+// GET /api/policies
+func handleGetAllPolicies(w http.ResponseWriter, r *http.Request) {
+	policies, err := getAllSanitizerPolicies()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error": "Politikalar okunamadı"}`))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(policies)
+}
+
+// GET /api/policies/{id}
+func handleGetPolicy(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	p, err := loadSanitizerPolicy(id)
+	if err == ErrConfigNotFound {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error": "Politika bulunamadı"}`))
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error": "YAML parse hatası"}`))
+		return
+	}
+	body, err := renderValue(p)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error": "JSON'a çevirilemedi"}`))
+		return
+	}
+	writeRendered(w, r, body)
+}
+
+// POST /api/policies
+func handlePostPolicy(w http.ResponseWriter, r *http.Request) {
+	p, ok := decodeSanitizerPolicy(w, r)
+	if !ok {
+		return
+	}
+	if p.ID == "" {
+		writeFieldError(w, "id", codeRequired, "zorunlu alan")
+		return
+	}
+	if err := validateConfigID(KindPolicy, p.ID); err != nil {
+		writeInvalidID(w, err)
+		return
+	}
+	defer lockConfig(KindPolicy, p.ID)()
+	if err := saveSanitizerPolicy(p, requestAuthor(r)); err != nil {
+		if writeValidationError(w, err) {
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error": "Dosya yazılamadı"}`))
+		return
+	}
+	setStoredETag(w, KindPolicy, p.ID)
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte(`{"message": "Politika eklendi"}`))
+}
+
+// PUT /api/policies/{id}
+func handlePutPolicy(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	p, ok := decodeSanitizerPolicy(w, r)
+	if !ok {
+		return
+	}
+	if _, ok := reconcileBodyID(w, p.ID, id); !ok {
+		return
+	}
+	p.ID = id
+	defer lockConfig(KindPolicy, id)()
+	if !checkIfMatch(w, r, KindPolicy, id) {
+		return
+	}
+	// Yerleşik varsayılan store'da olmasa da vardır (GET onu döndürür); upsert gerekmeden güncellenir
+	created := false
+	if id != defaultPolicyID {
+		if created, ok = checkPutTarget(w, r, KindPolicy, id); !ok {
+			return
+		}
+	}
+	if err := saveSanitizerPolicy(p, requestAuthor(r)); err != nil {
+		if writeValidationError(w, err) {
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error": "Dosya yazılamadı"}`))
+		return
+	}
+	setStoredETag(w, KindPolicy, id)
+	if created {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"message": "Politika eklendi"}`))
+		return
+	}
+	w.Write([]byte(`{"message": "Politika güncellendi"}`))
+}
+
+// DELETE /api/policies/{id}
+func handleDeletePolicy(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	defer lockConfig(KindPolicy, id)()
+	if !checkIfMatch(w, r, KindPolicy, id) {
+		return
+	}
+	if err := deleteConfig(KindPolicy, id, requestAuthor(r)); err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error": "Politika silinemedi"}`))
+		return
+	}
+	w.Write([]byte(`{"message": "Politika silindi"}`))
+}
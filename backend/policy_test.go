@@ -0,0 +1,197 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSanitizerPolicyPerHostAndConfig(t *testing.T) {
+	s := useMemoryStore(t)
+	putRefConfigs(t, s, "x.yaml")
+	router := newRouter()
+
+	video := `{"id": "video", "hosts": ["video.example.com"],
+		"elements": {"div": [], "iframe": ["src", "width", "height", "allowfullscreen"]},
+		"frame_hosts": ["www.youtube.com", "*.youtube-nocookie.com"]}`
+	if w := doRequest(router, "POST", "/api/policies", video, nil); w.Code != http.StatusCreated {
+		t.Fatalf("POST policy: %d %s", w.Code, w.Body.String())
+	}
+	if w := doRequest(router, "POST", "/api/policies", `{"id": "text", "elements": {"b": [], "i": []}}`, nil); w.Code != http.StatusCreated {
+		t.Fatalf("POST policy: %d %s", w.Code, w.Body.String())
+	}
+
+	element := `<div><iframe src=\"https://www.youtube.com/embed/x\" width=\"560\"></iframe>` +
+		`<iframe src=\"https://evil.example/embed\"></iframe><b>kalın</b></div>`
+
+	// Host eşleşmesiyle seçilen politika
+	body := `{"id": "videos", "name": "Videolar", "datasource": {"hosts": {"video.example.com": "x.yaml"}},
+		"actions": [{"type": "insert", "position": "append", "target": "body", "element": "` + element + `"}]}`
+	if w := doRequest(router, "POST", "/api/pages", body, nil); w.Code != http.StatusCreated {
+		t.Fatalf("POST pages: %d %s", w.Code, w.Body.String())
+	}
+	w := doRequest(router, "GET", "/api/pages/videos", "", nil)
+	var pages PagesConfig
+	if err := json.Unmarshal(w.Body.Bytes(), &pages); err != nil {
+		t.Fatalf("GET pages: %d %s", w.Code, w.Body.String())
+	}
+	if pages.AppliedSanitizerPolicy != "video" {
+		t.Errorf("applied policy = %q", pages.AppliedSanitizerPolicy)
+	}
+	got := pages.Actions[0].(*InsertAction).Element
+	if want := `<div><iframe src="https://www.youtube.com/embed/x" width="560"></iframe>kalın</div>`; got != want {
+		t.Errorf("element = %q, want %q", got, want)
+	}
+
+	// Config'te açıkça seçilen politika host eşleşmesinden önce gelir
+	body = `{"id": "text-only", "sanitizer_policy": "text", "datasource": {"hosts": {"video.example.com": "x.yaml"}},
+		"actions": [{"type": "replace", "selector": "#x", "newElement": "` + element + `"}]}`
+	if w := doRequest(router, "POST", "/api/configuration", body, nil); w.Code != http.StatusCreated {
+		t.Fatalf("POST config: %d %s", w.Code, w.Body.String())
+	}
+	w = doRequest(router, "GET", "/api/configuration/text-only", "", nil)
+	var cfg map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &cfg)
+	if cfg["applied_sanitizer_policy"] != "text" {
+		t.Errorf("applied policy = %v", cfg["applied_sanitizer_policy"])
+	}
+	if strings.Contains(w.Body.String(), "iframe") || !strings.Contains(w.Body.String(), `\u003cb\u003ekalın\u003c/b\u003e`) {
+		t.Errorf("text policy not applied: %s", w.Body.String())
+	}
+
+	// Hiçbir politika seçilmemişse varsayılan uygulanır
+	body = `{"id": "plain", "actions": [{"type": "replace", "selector": "#x", "newElement": "` + element + `"}]}`
+	doRequest(router, "POST", "/api/specific", body, nil)
+	w = doRequest(router, "GET", "/api/specific/plain", "", nil)
+	if !strings.Contains(w.Body.String(), `"applied_sanitizer_policy":"default"`) || strings.Contains(w.Body.String(), "iframe") {
+		t.Errorf("default policy not applied: %s", w.Body.String())
+	}
+
+	// Uygulanan politika saklanmaz, okurken hesaplanır; istemcinin gönderdiği değer yok sayılır
+	for _, kind := range []ConfigKind{KindGeneral, KindSpecific, KindPages} {
+		for _, id := range []string{"videos", "text-only", "plain"} {
+			if b, err := store.Get(kind, id); err == nil && strings.Contains(string(b), "applied_sanitizer_policy") {
+				t.Errorf("%s/%s stored applied policy:\n%s", kind, id, b)
+			}
+		}
+	}
+	for _, kind := range []ConfigKind{KindGeneral, KindSpecific, KindPages} {
+		path := map[ConfigKind]string{KindGeneral: "/api/configuration", KindSpecific: "/api/specific", KindPages: "/api/pages"}[kind]
+		w = doRequest(router, "POST", path, `{"id": "client", "applied_sanitizer_policy": "video", "actions": []}`, nil)
+		if w.Code != http.StatusCreated {
+			t.Errorf("%s client applied policy: %d %s", path, w.Code, w.Body.String())
+		}
+		if b, _ := store.Get(kind, "client"); strings.Contains(string(b), "applied_sanitizer_policy") {
+			t.Errorf("%s stored client value:\n%s", path, b)
+		}
+		// GET yanıtı olduğu gibi geri yazılabilir
+		got := doRequest(router, "GET", path+"/client", "", nil).Body.String()
+		if !strings.Contains(got, `"applied_sanitizer_policy":"default"`) {
+			t.Errorf("%s GET: %s", path, got)
+		}
+		if w := doRequest(router, "PUT", path+"/client", got, nil); w.Code != http.StatusOK {
+			t.Errorf("%s GET → PUT: %d %s", path, w.Code, w.Body.String())
+		}
+	}
+	w = doRequest(router, "GET", "/api/pages/resolve?host=video.example.com", "", nil)
+	if !strings.Contains(w.Body.String(), `"applied_sanitizer_policy":"video"`) {
+		t.Errorf("resolve applied policy: %s", w.Body.String())
+	}
+
+	// Bilinmeyen politika 422
+	body = `{"id": "bad", "sanitizer_policy": "nope", "actions": []}`
+	w = doRequest(router, "POST", "/api/configuration", body, nil)
+	if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), codeUnknownPolicy) {
+		t.Errorf("unknown policy: %d %s", w.Code, w.Body.String())
+	}
+
+	// Aynı hosta bağlı iki politika: config açıkça seçmeli
+	doRequest(router, "POST", "/api/policies", `{"id": "video2", "hosts": ["VIDEO.example.com"], "elements": {"p": []}}`, nil)
+	w = doRequest(router, "PUT", "/api/pages/videos", `{"name": "Videolar", "datasource": {"hosts": {"video.example.com": "x.yaml"}}}`, nil)
+	if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), codeAmbiguousPolicy) {
+		t.Errorf("ambiguous policy: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestSanitizerPolicyValidation(t *testing.T) {
+	useMemoryStore(t)
+	router := newRouter()
+
+	body := `{"id": "unsafe", "elements": {"script": [], "a": ["href", "onclick"]},
+		"global_attributes": ["style"], "url_schemes": ["https", "javascript"]}`
+	w := doRequest(router, "POST", "/api/policies", body, nil)
+	var resp struct {
+		Errors ValidationErrors `json:"errors"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusUnprocessableEntity || len(resp.Errors) != 4 {
+		t.Fatalf("unsafe policy: %d %s", w.Code, w.Body.String())
+	}
+	for i, path := range []string{"elements.a[1]", "elements.script", "global_attributes[0]", "url_schemes[1]"} {
+		if resp.Errors[i].Path != path || resp.Errors[i].Code != codeForbiddenValue {
+			t.Errorf("error %d = %+v, want path %s", i, resp.Errors[i], path)
+		}
+	}
+
+	if w := doRequest(router, "POST", "/api/policies", `{"id": "x", "element": {}}`, nil); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown field: %d %s", w.Code, w.Body.String())
+	}
+
+	// Yerleşik varsayılan listede görünür ve okunabilir
+	w = doRequest(router, "GET", "/api/policies/default", "", nil)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"id":"default"`) {
+		t.Errorf("GET default: %d %s", w.Code, w.Body.String())
+	}
+	if w := doRequest(router, "GET", "/api/policies/missing", "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("GET missing: %d", w.Code)
+	}
+	// GET'in döndürdüğü yerleşik varsayılan var sayılır; PUT ?upsert=true istemez
+	if w := doRequest(router, "PUT", "/api/policies/default", `{"elements": {"p": []}}`, nil); w.Code != http.StatusOK {
+		t.Errorf("PUT default: %d %s", w.Code, w.Body.String())
+	}
+	if w := doRequest(router, "PUT", "/api/policies/missing", `{"elements": {"p": []}}`, nil); w.Code != http.StatusNotFound {
+		t.Errorf("PUT missing: %d %s", w.Code, w.Body.String())
+	}
+}
+
+// Politika kayıttan sonra değişirse yanıttaki içerik bildirilen politikayla temizlenmiş olmalı
+func TestAppliedPolicyResanitizesOnRead(t *testing.T) {
+	useMemoryStore(t)
+	router := newRouter()
+
+	for _, req := range []struct{ url, body string }{
+		{"/api/policies", `{"id": "rich", "elements": {"div": ["class"], "b": []}}`},
+		{"/api/configuration", `{"id": "g", "sanitizer_policy": "rich", "actions": [
+			{"type": "replace", "selector": ".x", "newElement": "<div class=\"box\"><b>x</b></div>"}]}`},
+		{"/api/pages", `{"id": "p", "sanitizer_policy": "rich", "actions": [
+			{"type": "insert", "target": "body", "position": "append", "element": "<div class=\"box\"><b>y</b></div>"}]}`},
+	} {
+		if w := doRequest(router, "POST", req.url, req.body, nil); w.Code != http.StatusCreated {
+			t.Fatalf("POST %s: %d %s", req.url, w.Code, w.Body.String())
+		}
+	}
+	if w := doRequest(router, "GET", "/api/configuration/g", "", nil); !strings.Contains(w.Body.String(), `class=\"box\"`) {
+		t.Fatalf("before: %s", w.Body.String())
+	}
+
+	// Politika daraltılır: saklanan içerik değişmez ama yanıtlar yeni politikayla temizlenir
+	if w := doRequest(router, "PUT", "/api/policies/rich", `{"elements": {"b": []}}`, nil); w.Code != http.StatusOK {
+		t.Fatalf("PUT policy: %d %s", w.Code, w.Body.String())
+	}
+	for _, url := range []string{"/api/configuration/g", "/api/configuration/all", "/api/pages/p", "/api/pages/all"} {
+		body := doRequest(router, "GET", url, "", nil).Body.String()
+		if strings.Contains(body, "box") || !strings.Contains(body, `"applied_sanitizer_policy":"rich"`) {
+			t.Errorf("%s: %s", url, body)
+		}
+	}
+
+	// Politika silinirse varsayılana düşülür (varsayılan div/class'a izin verir)
+	if w := doRequest(router, "DELETE", "/api/policies/rich", "", nil); w.Code != http.StatusOK {
+		t.Fatalf("DELETE policy: %d %s", w.Code, w.Body.String())
+	}
+	body := doRequest(router, "GET", "/api/configuration/g", "", nil).Body.String()
+	if !strings.Contains(body, `"applied_sanitizer_policy":"default"`) {
+		t.Errorf("after delete: %s", body)
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Datasource değerleri (ör. blog_post.yaml ya da [cart.yaml, checkout.yaml]) store'daki genel
+// config'lere referanstır; id, dosya adından .yaml/.yml uzantısı atılarak bulunur.
+func refConfigID(ref string) string {
+	for _, ext := range []string{".yaml", ".yml"} {
+		if strings.HasSuffix(ref, ext) {
+			return strings.TrimSuffix(ref, ext)
+		}
+	}
+	return ref
+}
+
+// Datasource değerindeki referans listesi; değer dosya adı ya da dosya adı listesi olmalı
+func refList(value interface{}, path string) ([]string, ValidationErrors) {
+	switch v := value.(type) {
+	case string:
+		return []string{v}, nil
+	case []interface{}:
+		refs := make([]string, 0, len(v))
+		var errs ValidationErrors
+		for i, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				errs = append(errs, ValidationError{Path: fmt.Sprintf("%s[%d]", path, i), Code: codeInvalidType, Message: "dosya adı olmalı"})
+				continue
+			}
+			refs = append(refs, s)
+		}
+		return refs, errs
+	}
+	return nil, ValidationErrors{{Path: path, Code: codeInvalidType, Message: "dosya adı ya da dosya adı listesi olmalı"}}
+}
+
+// Pages configlerinin datasource'larında referans verilen genel config id'leri. Bunlar
+// belirli sayfalar için yüklenir; site genelinde uygulanan /api/configuration/all listesine girmez.
+func referencedConfigIDs() (map[string]bool, error) {
+	configs, err := getAllPagesConfigs()
+	if err != nil {
+		return nil, err
+	}
+	ids := map[string]bool{}
+	for _, cfg := range configs {
+		ds := cfg.Datasource
+		for _, m := range []map[string]interface{}{ds.Pages, ds.URLs, ds.Hosts} {
+			for _, v := range m {
+				refs, _ := refList(v, "")
+				for _, ref := range refs {
+					ids[refConfigID(ref)] = true
+				}
+			}
+		}
+	}
+	return ids, nil
+}
+
+// Referans verilen dosyaları yükler, aksiyonlarını doğrulayıp verilen sanitizer ile temizler
+// ve sırayla birleştirir. Eksik dosya ve geçersiz aksiyonlar doğrulama hatası olarak döner;
+// aksiyon hatalarının yolu refs["<dosya>"].actions[i] biçimindedir.
+func loadRefActions(value interface{}, path string, sanitizer *Sanitizer) (Actions, []string, error) {
+	refs, errs := refList(value, path)
+	var out Actions
+	for _, ref := range refs {
+		id := refConfigID(ref)
+		if err := validateConfigID(KindGeneral, id); err != nil {
+			errs = append(errs, ValidationError{Path: path, Code: codeInvalidFormat, Message: ref + ": " + err.Error()})
+			continue
+		}
+		cfg, err := loadConfig(KindGeneral, id)
+		if err == ErrConfigNotFound {
+			errs = append(errs, ValidationError{Path: path, Code: codeMissingRef, Message: "referans verilen dosya bulunamadı: " + ref})
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		// Referans verilen config de extends ile aksiyon devralabilir
+		cfg, err = flattenConfig(KindGeneral, cfg)
+		if err == nil && cfg["actions"] == nil {
+			continue
+		}
+		var actions Actions
+		if err == nil {
+			actions, err = decodeActions(cfg["actions"])
+		}
+		if err == nil {
+			_, err = validateAndSanitizeActions(actions, sanitizer, false)
+		}
+		if err != nil {
+			verrs, ok := asValidationErrors(err)
+			if !ok {
+				return nil, nil, err
+			}
+			for _, e := range verrs {
+				e.Path = fmt.Sprintf("refs[%q].%s", ref, e.Path)
+				errs = append(errs, e)
+			}
+			continue
+		}
+		out = append(out, actions...)
+	}
+	if len(errs) > 0 {
+		return nil, nil, errs
+	}
+	return out, refs, nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// Resolve testleri için referans verilen dosyaları boş aksiyon listesiyle store'a koy
+func putRefConfigs(t *testing.T, s *MemoryStore, refs ...string) {
+	t.Helper()
+	for _, ref := range refs {
+		id := refConfigID(ref)
+		if err := s.Put(KindGeneral, id, []byte("id: "+id+"\nactions: []\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// Referans verilen genel configler site geneli listeye girmez, doğrudan okunabilir
+func TestReferencedConfigsExcludedFromAll(t *testing.T) {
+	s := useMemoryStore(t)
+	putRefConfigs(t, s, "blog_post.yaml", "cart.yaml", "global.yaml")
+	router := newRouter()
+
+	if w := doRequest(router, "POST", "/api/pages", `{"id": "blog", "datasource": {
+		"pages": {"post": "blog_post.yaml"}, "urls": {"/cart": ["cart.yaml"]}}, "actions": []}`, nil); w.Code != http.StatusCreated {
+		t.Fatalf("POST pages: %d %s", w.Code, w.Body.String())
+	}
+	w := doRequest(router, "GET", "/api/configuration/all", "", nil)
+	var all []map[string]interface{}
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &all) != nil {
+		t.Fatalf("all: %d %s", w.Code, w.Body.String())
+	}
+	var ids []string
+	for _, cfg := range all {
+		ids = append(ids, fmt.Sprint(cfg["id"]))
+	}
+	if got := strings.Join(ids, ","); got != "global,blog" {
+		t.Errorf("all ids: %s", got)
+	}
+	if w := doRequest(router, "GET", "/api/configuration/cart", "", nil); w.Code != http.StatusOK {
+		t.Errorf("referenced config: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestResolveMergesReferencedActions(t *testing.T) {
+	s := useMemoryStore(t)
+	router := newRouter()
+
+	for _, body := range []string{
+		`{"id": "blog_post", "actions": [{"type": "remove", "selector": ".ad"}, {"type": "insert", "target": "h1", "position": "after", "element": "<p>ok</p><script>x()</script>"}]}`,
+		`{"id": "cart", "actions": [{"type": "remove", "selector": ".upsell"}]}`,
+		`{"id": "checkout", "actions": [{"type": "replace", "selector": ".total", "newElement": "<b>Toplam</b>"}]}`,
+	} {
+		if w := doRequest(router, "POST", "/api/configuration", body, nil); w.Code != http.StatusCreated {
+			t.Fatalf("POST config: %d %s", w.Code, w.Body.String())
+		}
+	}
+	// Geçersiz aksiyonlu dosya doğrudan store'a yazılır (API bunu kabul etmezdi)
+	if err := s.Put(KindGeneral, "broken", []byte("id: broken\nactions:\n  - type: remove\n")); err != nil {
+		t.Fatal(err)
+	}
+	pages := `{"id": "blog", "actions": [{"type": "remove", "selector": ".banner"}], "datasource": {"pages": {
+		"post": "blog_post.yaml", "checkout": ["cart.yaml", "checkout.yaml"], "gone": "missing.yaml",
+		"broken": "broken.yaml", "escape": "../specific/x.yaml", "empty": []}}}`
+	if w := doRequest(router, "POST", "/api/pages", pages, nil); w.Code != http.StatusCreated {
+		t.Fatalf("POST pages: %d %s", w.Code, w.Body.String())
+	}
+
+	type result struct {
+		ConfigRef        interface{}              `json:"config_ref"`
+		LoadedRefs       []string                 `json:"loaded_refs"`
+		EffectiveActions []map[string]interface{} `json:"effective_actions"`
+	}
+	resolve := func(page string) result {
+		t.Helper()
+		w := doRequest(router, "GET", "/api/pages/resolve?page="+page, "", nil)
+		var res result
+		if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &res) != nil {
+			t.Fatalf("%s: %d %s", page, w.Code, w.Body.String())
+		}
+		return res
+	}
+	selectors := func(res result) string {
+		var out []string
+		for _, a := range res.EffectiveActions {
+			sel, _ := a["selector"].(string)
+			if target, ok := a["target"].(string); ok {
+				sel = target
+			}
+			out = append(out, sel)
+		}
+		return strings.Join(out, ",")
+	}
+
+	// Önce config'in kendi aksiyonları, ardından referans verilen dosyanınkiler
+	res := resolve("post")
+	if got := selectors(res); got != ".banner,.ad,h1" || res.ConfigRef != "blog_post.yaml" {
+		t.Errorf("post: %s %v", got, res.ConfigRef)
+	}
+	if html := res.EffectiveActions[2]["element"].(string); strings.Contains(html, "script") {
+		t.Errorf("referenced html not sanitized: %q", html)
+	}
+
+	res = resolve("checkout")
+	if got := selectors(res); got != ".banner,.upsell,.total" || strings.Join(res.LoadedRefs, ",") != "cart.yaml,checkout.yaml" {
+		t.Errorf("checkout: %s %v", got, res.LoadedRefs)
+	}
+	if res = resolve("empty"); selectors(res) != ".banner" {
+		t.Errorf("empty list: %s", selectors(res))
+	}
+
+	tests := []struct {
+		page, path, code string
+	}{
+		{"gone", `datasource.pages["gone"]`, codeMissingRef},
+		{"broken", `refs["broken.yaml"].actions[0].selector`, codeRequired},
+		{"escape", `datasource.pages["escape"]`, codeInvalidFormat},
+	}
+	for _, tt := range tests {
+		w := doRequest(router, "GET", "/api/pages/resolve?page="+tt.page, "", nil)
+		var body struct {
+			Errors ValidationErrors `json:"errors"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &body)
+		if w.Code != http.StatusUnprocessableEntity || len(body.Errors) != 1 || body.Errors[0].Path != tt.path || body.Errors[0].Code != tt.code {
+			t.Errorf("%s: %d %s", tt.page, w.Code, w.Body.String())
+		}
+	}
+}
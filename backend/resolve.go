@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Eşleşme türleri, öncelik sırasıyla (page > url > host)
+const (
+	resolveByPage = iota
+	resolveByURL
+	resolveByHost
+)
+
+var resolveKindNames = []string{"page", "url", "host"}
+
+// resolveCandidate: resolve isteğine uyan bir pages config'i ve onun en iyi eşleşmesi
+type resolveCandidate struct {
+	Config         PagesConfig `json:"-"`
+	ID             string      `json:"id"`
+	Rank           int         `json:"rank"`
+	MatchedBy      string      `json:"matched_by"`
+	MatchedValue   string      `json:"matched_value"`
+	MatchedPattern string      `json:"matched_pattern,omitempty"`
+	ConfigRef      interface{} `json:"config_ref"`
+	Priority       int         `json:"priority"`
+
+	kind int
+	url  *urlMatch
+	host *hostPattern
+}
+
+// Config'in istekle en iyi eşleşmesi; hiçbir tür eşleşmezse nil
+func resolveCandidateFor(cfg PagesConfig, page, url, host string) *resolveCandidate {
+	c := &resolveCandidate{Config: cfg, ID: cfg.ID, Priority: cfg.Priority}
+	if ref, ok := cfg.Datasource.Pages[page]; ok && page != "" {
+		c.kind, c.MatchedBy, c.MatchedValue, c.ConfigRef = resolveByPage, "page", page, ref
+		return c
+	}
+	if url != "" {
+		if m := bestURLMatch(cfg, requestPath(url)); m != nil {
+			c.kind, c.url, c.MatchedValue, c.MatchedPattern, c.ConfigRef = resolveByURL, m, url, m.Pattern.Key, m.Ref
+			c.MatchedBy = "url"
+			if m.Kind != urlMatchExact {
+				c.MatchedBy = "url_" + m.Kind
+			}
+			return c
+		}
+	}
+	if host != "" {
+		if p := bestHostPattern(cfg.Datasource.Hosts, host); p != nil {
+			c.kind, c.host, c.MatchedValue, c.MatchedPattern, c.ConfigRef = resolveByHost, p, host, p.Key, cfg.Datasource.Hosts[p.Key]
+			c.MatchedBy = "host"
+			if p.wildcard {
+				c.MatchedBy = "host_wildcard"
+			}
+			return c
+		}
+	}
+	return nil
+}
+
+// Eşleşen datasource girdisinin yolu (ör. datasource.pages["post"]); referans hataları bu yolla raporlanır
+func (c *resolveCandidate) refPath() string {
+	key := c.MatchedPattern
+	if c.kind == resolveByPage {
+		key = c.MatchedValue
+	}
+	return fmt.Sprintf("datasource.%s[%q]", []string{"pages", "urls", "hosts"}[c.kind], key)
+}
+
+// Aynı türdeki iki eşleşmenin özgüllük karşılaştırması (page eşleşmeleri hep eşittir)
+func (a *resolveCandidate) specificity(b *resolveCandidate) int {
+	switch a.kind {
+	case resolveByURL:
+		return a.url.specificity(b.url)
+	case resolveByHost:
+		return a.host.specificity(b.host)
+	}
+	return 0
+}
+
+// a'nın b'den önce gelip gelmediği ve karar veren ölçüt: tür, özgüllük, priority, id
+func compareCandidates(a, b *resolveCandidate) (bool, string) {
+	if a.kind != b.kind {
+		return a.kind < b.kind, "kind"
+	}
+	if c := a.specificity(b); c != 0 {
+		return c > 0, "specificity"
+	}
+	if a.Priority != b.Priority {
+		return a.Priority > b.Priority, "priority"
+	}
+	return a.ID < b.ID, "id"
+}
+
+// İsteğe uyan tüm configler, kazanan başta olacak şekilde sıralı
+func rankResolveCandidates(configs []PagesConfig, page, url, host string) []*resolveCandidate {
+	var out []*resolveCandidate
+	for _, cfg := range configs {
+		if c := resolveCandidateFor(cfg, page, url, host); c != nil {
+			out = append(out, c)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		before, _ := compareCandidates(out[i], out[j])
+		return before
+	})
+	for i, c := range out {
+		c.Rank = i + 1
+	}
+	return out
+}
+
+// Kazananın neden kazandığını ikinciyle karşılaştırarak açıkla
+func explainResolve(ranked []*resolveCandidate) string {
+	if len(ranked) == 0 {
+		return ""
+	}
+	win := ranked[0]
+	if len(ranked) == 1 {
+		return fmt.Sprintf("%s eşleşen tek config (%s: %s)", win.ID, win.MatchedBy, describeCandidateMatch(win))
+	}
+	next := ranked[1]
+	var reason string
+	switch _, by := compareCandidates(win, next); by {
+	case "kind":
+		reason = fmt.Sprintf("%s eşleşmesi %s eşleşmesinden önce gelir (page > url > host)", resolveKindNames[win.kind], resolveKindNames[next.kind])
+	case "specificity":
+		reason = fmt.Sprintf("%s %s, %s %s eşleşmesinden daha özgül", win.MatchedBy, describeCandidateMatch(win), next.MatchedBy, describeCandidateMatch(next))
+	case "priority":
+		reason = fmt.Sprintf("eşleşmeler eşit özgüllükte, priority %d > %d", win.Priority, next.Priority)
+	default:
+		reason = fmt.Sprintf("eşleşmeler ve priority eşit, id sırası %s < %s", win.ID, next.ID)
+	}
+	return fmt.Sprintf("%s kazandı, %s önüne geçti: %s", win.ID, next.ID, reason)
+}
+
+func describeCandidateMatch(c *resolveCandidate) string {
+	if c.MatchedPattern != "" {
+		return fmt.Sprintf("%q", c.MatchedPattern)
+	}
+	return fmt.Sprintf("%q", c.MatchedValue)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestResolveRanksAllConfigs(t *testing.T) {
+	s := useMemoryStore(t)
+	putRefConfigs(t, s, "blog.yaml", "home.yaml", "cart.yaml", "list.yaml", "mirror.yaml", "mirror2.yaml")
+	router := newRouter()
+
+	for _, body := range []string{
+		// "a" id sırasında önce gelir; eski davranışta host eşleşmesi page eşleşmesini geçerdi
+		`{"id": "a-blog", "datasource": {"hosts": {"shop.example.com": "blog.yaml"}, "urls": {"/**": "home.yaml"}}}`,
+		`{"id": "b-shop", "datasource": {"pages": {"cart": "cart.yaml"}, "urls": {"/products/": "list.yaml"}}}`,
+		`{"id": "c-mirror", "priority": 5, "datasource": {"hosts": {"shop.example.com": "mirror.yaml"}}}`,
+		`{"id": "d-mirror", "datasource": {"hosts": {"shop.example.com": "mirror2.yaml"}}}`,
+	} {
+		if w := doRequest(router, "POST", "/api/pages", body, nil); w.Code != http.StatusCreated {
+			t.Fatalf("POST: %d %s", w.Code, w.Body.String())
+		}
+	}
+
+	type result struct {
+		Config      PagesConfig         `json:"config"`
+		MatchedBy   string              `json:"matched_by"`
+		Candidates  []*resolveCandidate `json:"candidates"`
+		Explanation string              `json:"explanation"`
+	}
+	resolve := func(query string) result {
+		t.Helper()
+		w := doRequest(router, "GET", "/api/pages/resolve?"+query, "", nil)
+		var res result
+		if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &res) != nil {
+			t.Fatalf("%s: %d %s", query, w.Code, w.Body.String())
+		}
+		return res
+	}
+	ids := func(res result) string {
+		var out []string
+		for _, c := range res.Candidates {
+			out = append(out, c.ID+":"+c.MatchedBy)
+		}
+		return strings.Join(out, ",")
+	}
+
+	res := resolve("page=cart&host=shop.example.com")
+	if res.Config.ID != "b-shop" || res.MatchedBy != "page" {
+		t.Errorf("page should beat host: %s %s", res.Config.ID, res.MatchedBy)
+	}
+	if got := ids(res); got != "b-shop:page,c-mirror:host,a-blog:host,d-mirror:host" {
+		t.Errorf("ranking = %s", got)
+	}
+	if !strings.Contains(res.Explanation, "page eşleşmesi host eşleşmesinden önce gelir") {
+		t.Errorf("explanation = %q", res.Explanation)
+	}
+
+	// Aynı türde daha özgül url kazanır
+	res = resolve("url=/products/shoes&host=shop.example.com")
+	if got := ids(res); got != "b-shop:url_prefix,a-blog:url_glob,c-mirror:host,d-mirror:host" {
+		t.Errorf("ranking = %s", got)
+	}
+	if !strings.Contains(res.Explanation, `url_prefix "/products/", url_glob "/**" eşleşmesinden daha özgül`) {
+		t.Errorf("explanation = %q", res.Explanation)
+	}
+
+	// Eşit eşleşmelerde priority, sonra id
+	res = resolve("host=shop.example.com")
+	if got := ids(res); got != "c-mirror:host,a-blog:host,d-mirror:host" || !strings.Contains(res.Explanation, "priority 5 > 0") {
+		t.Errorf("ranking = %s, explanation = %q", got, res.Explanation)
+	}
+	if res.Candidates[0].Rank != 1 || res.Candidates[2].Rank != 3 {
+		t.Errorf("ranks = %+v", res.Candidates)
+	}
+	blog, _ := loadPagesConfig("a-blog")
+	mirror, _ := loadPagesConfig("d-mirror")
+	ranked := rankResolveCandidates([]PagesConfig{*mirror, *blog}, "", "", "shop.example.com")
+	if explainResolve(ranked) != "a-blog kazandı, d-mirror önüne geçti: eşleşmeler ve priority eşit, id sırası a-blog < d-mirror" {
+		t.Errorf("explanation = %q", explainResolve(ranked))
+	}
+
+	if w := doRequest(router, "POST", "/api/pages", `{"id": "bad", "priority": "high"}`, nil); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("non-integer priority: %d %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,408 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// SanitizerPolicy: newElement/element içeriklerinde izin verilen HTML.
+// Listede olmayan elementler kaldırılır (içerikleri korunur); tehlikeli
+// elementler (script, style, iframe...) içerikleriyle birlikte atılır.
+type SanitizerPolicy struct {
+	ID string `yaml:"id" json:"id"`
+	// Bu hostlardaki configlere (datasource.hosts) açıkça politika seçilmemişse uygulanır
+	Hosts []string `yaml:"hosts,omitempty" json:"hosts,omitempty"`
+	// Element adı -> o elemente özel izinli attribute'lar
+	Elements map[string][]string `yaml:"elements" json:"elements"`
+	// Tüm izinli elementlerde kabul edilen attribute'lar; "data-*" gibi önek kalıpları desteklenir
+	GlobalAttributes []string `yaml:"global_attributes,omitempty" json:"global_attributes,omitempty"`
+	// href/src gibi URL attribute'larında izin verilen şemalar (göreli URL'ler her zaman serbest; boşsa varsayılanlar)
+	URLSchemes []string `yaml:"url_schemes,omitempty" json:"url_schemes,omitempty"`
+	// iframe'e izin veriliyorsa src'nin işaret edebileceği hostlar ("*.example.com" alt alan adlarını kapsar)
+	FrameHosts []string `yaml:"frame_hosts,omitempty" json:"frame_hosts,omitempty"`
+}
+
+// Varsayılan politika: metin biçimlendirme, liste, tablo, bağlantı ve görsel
+var DefaultSanitizerPolicy = SanitizerPolicy{
+	ID: defaultPolicyID,
+	Elements: map[string][]string{
+		"a": {"href", "target", "rel"}, "abbr": nil, "article": nil, "aside": nil,
+		"b": nil, "blockquote": {"cite"}, "br": nil, "button": {"type"},
+		"caption": nil, "cite": nil, "code": nil, "col": {"span"}, "colgroup": {"span"},
+		"dd": nil, "del": {"cite", "datetime"}, "details": {"open"}, "dfn": nil, "div": nil,
+		"dl": nil, "dt": nil, "em": nil, "figcaption": nil, "figure": nil, "footer": nil,
+		"h1": nil, "h2": nil, "h3": nil, "h4": nil, "h5": nil, "h6": nil, "header": nil,
+		"hr": nil, "i": nil, "img": {"src", "alt", "width", "height", "loading"},
+		"ins": {"cite", "datetime"}, "kbd": nil, "label": {"for"}, "li": {"value"},
+		"main": nil, "mark": nil, "nav": nil, "ol": {"start", "reversed", "type"},
+		"p": nil, "pre": nil, "q": {"cite"}, "s": nil, "section": nil, "small": nil,
+		"span": nil, "strong": nil, "sub": nil, "summary": nil, "sup": nil,
+		"table": nil, "tbody": nil, "td": {"colspan", "rowspan"}, "tfoot": nil,
+		"th": {"colspan", "rowspan", "scope"}, "thead": nil, "time": {"datetime"},
+		"tr": nil, "u": nil, "ul": nil,
+	},
+	GlobalAttributes: []string{"class", "id", "title", "lang", "dir", "role", "aria-*", "data-*"},
+	URLSchemes:       []string{"http", "https", "mailto", "tel"},
+}
+
+// Politika ne derse desin hiçbir zaman çıktıya yazılmayan elementler
+var forbiddenElements = map[string]bool{
+	"script": true, "style": true, "base": true, "meta": true, "link": true,
+	"object": true, "embed": true, "applet": true, "frame": true, "frameset": true,
+	"noscript": true, "noembed": true, "noframes": true, "template": true,
+	"plaintext": true, "xmp": true, "svg": true, "math": true,
+}
+
+// İzin verilmediğinde içerikleriyle birlikte atılan elementler
+var dropContentElements = map[string]bool{
+	"iframe": true, "title": true, "textarea": true, "select": true, "option": true,
+	"video": true, "audio": true, "canvas": true, "form": true, "input": true,
+}
+
+// Değeri URL olarak yorumlanan attribute'lar
+var urlAttributes = map[string]bool{
+	"href": true, "src": true, "cite": true, "action": true, "formaction": true,
+	"poster": true, "background": true, "xlink:href": true, "longdesc": true, "ping": true,
+}
+
+// Kapanış etiketi olmayan (void) elementler
+var voidElements = map[string]bool{
+	"area": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "source": true, "track": true, "wbr": true,
+}
+
+// Sanitizer: bir politikadan derlenmiş, eşzamanlı kullanıma uygun temizleyici
+type Sanitizer struct {
+	policy     SanitizerPolicy
+	elements   map[string]map[string]bool
+	global     map[string]bool
+	prefixes   []string
+	schemes    map[string]bool
+	dropInside map[string]bool
+	frameHosts []string
+}
+
+func NewSanitizer(policy SanitizerPolicy) *Sanitizer {
+	s := &Sanitizer{
+		policy:     policy,
+		elements:   map[string]map[string]bool{},
+		global:     map[string]bool{},
+		schemes:    map[string]bool{},
+		dropInside: map[string]bool{},
+	}
+	for name, attrs := range policy.Elements {
+		name = strings.ToLower(name)
+		if forbiddenElements[name] {
+			continue
+		}
+		set := map[string]bool{}
+		for _, a := range attrs {
+			set[strings.ToLower(a)] = true
+		}
+		s.elements[name] = set
+	}
+	for _, a := range policy.GlobalAttributes {
+		a = strings.ToLower(a)
+		if strings.HasSuffix(a, "*") {
+			s.prefixes = append(s.prefixes, strings.TrimSuffix(a, "*"))
+		} else {
+			s.global[a] = true
+		}
+	}
+	schemes := policy.URLSchemes
+	if len(schemes) == 0 {
+		schemes = DefaultSanitizerPolicy.URLSchemes
+	}
+	for _, scheme := range schemes {
+		s.schemes[strings.ToLower(scheme)] = true
+	}
+	for _, h := range policy.FrameHosts {
+		s.frameHosts = append(s.frameHosts, strings.ToLower(h))
+	}
+	for name := range forbiddenElements {
+		s.dropInside[name] = true
+	}
+	for name := range dropContentElements {
+		if _, ok := s.elements[name]; !ok {
+			s.dropInside[name] = true
+		}
+	}
+	return s
+}
+
+var defaultSanitizer = NewSanitizer(DefaultSanitizerPolicy)
+
+// HTML sanitizer: varsayılan politikaya göre temizler
+func sanitizeHTML(input string) string {
+	return defaultSanitizer.Sanitize(input)
+}
+
+// RemovedConstruct: sanitize sırasında atılan tek bir HTML yapısı
+type RemovedConstruct struct {
+	// element: içeriğiyle atılan element, tag: atılan etiket (içerik korunur),
+	// attribute: izinsiz attribute, url: şeması/hostu kabul edilmeyen URL, comment, doctype
+	Kind      string `json:"kind"`
+	Element   string `json:"element,omitempty"`
+	Attribute string `json:"attribute,omitempty"`
+	Value     string `json:"value,omitempty"`
+}
+
+func (c RemovedConstruct) String() string {
+	switch c.Kind {
+	case "element":
+		return "<" + c.Element + "> (içeriğiyle)"
+	case "tag":
+		return "<" + c.Element + ">"
+	case "attribute":
+		return c.Attribute + " (<" + c.Element + ">)"
+	case "url":
+		return c.Attribute + "=" + strconv.Quote(c.Value) + " (<" + c.Element + ">)"
+	}
+	return c.Kind
+}
+
+// Aynı yapı bir girdide birden çok kez geçse de raporda bir kez yer alır
+type removals []RemovedConstruct
+
+func (r *removals) add(c RemovedConstruct) {
+	for _, existing := range *r {
+		if existing == c {
+			return
+		}
+	}
+	*r = append(*r, c)
+}
+
+// Sanitize, girdiyi HTML tokenizer ile okuyup yalnızca izinli element ve attribute'ları yazar
+func (s *Sanitizer) Sanitize(input string) string {
+	out, _ := s.SanitizeWithReport(input)
+	return out
+}
+
+// SanitizeWithReport, Sanitize gibi çalışır ve atılan yapıları da döner.
+// Tırnak, escape ve kapanış etiketi gibi yazım normalleştirmeleri rapora girmez.
+func (s *Sanitizer) SanitizeWithReport(input string) (string, []RemovedConstruct) {
+	var out strings.Builder
+	var removed removals
+	z := html.NewTokenizer(strings.NewReader(input))
+	var open []string
+	skip, skipDepth := "", 0
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			// io.EOF dışında hata beklenmez (girdi bellekte)
+			break
+		}
+		tok := z.Token()
+
+		// Tehlikeli bir elementin içindeyiz: kapanışına kadar her şeyi at
+		if skip != "" {
+			switch {
+			case tt == html.StartTagToken && tok.Data == skip:
+				skipDepth++
+			case tt == html.EndTagToken && tok.Data == skip:
+				skipDepth--
+				if skipDepth == 0 {
+					skip = ""
+				}
+			}
+			continue
+		}
+
+		switch tt {
+		case html.TextToken:
+			out.WriteString(html.EscapeString(tok.Data))
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if s.dropInside[tok.Data] {
+				removed.add(RemovedConstruct{Kind: "element", Element: tok.Data})
+				if tt == html.StartTagToken && !voidElements[tok.Data] {
+					skip, skipDepth = tok.Data, 1
+				}
+				continue
+			}
+			allowed, ok := s.elements[tok.Data]
+			if !ok {
+				removed.add(RemovedConstruct{Kind: "tag", Element: tok.Data})
+				continue
+			}
+			tok.Attr = s.filterAttributes(tok.Data, tok.Attr, allowed, &removed)
+			// src'si kabul edilmeyen iframe boş bir çerçeve olarak kalmasın
+			if tok.Data == "iframe" && !hasAttribute(tok.Attr, "src") {
+				removed.add(RemovedConstruct{Kind: "element", Element: tok.Data})
+				if tt == html.StartTagToken {
+					skip, skipDepth = tok.Data, 1
+				}
+				continue
+			}
+			if voidElements[tok.Data] {
+				tok.Type = html.SelfClosingTagToken
+			} else {
+				tok.Type = html.StartTagToken
+				open = append(open, tok.Data)
+			}
+			out.WriteString(tok.String())
+		case html.EndTagToken:
+			// Yalnızca açık olan izinli elementler kapatılır; aradakiler de kapanır
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] != tok.Data {
+					continue
+				}
+				for j := len(open) - 1; j >= i; j-- {
+					out.WriteString("</" + open[j] + ">")
+				}
+				open = open[:i]
+				break
+			}
+		case html.CommentToken:
+			removed.add(RemovedConstruct{Kind: "comment"})
+		case html.DoctypeToken:
+			removed.add(RemovedConstruct{Kind: "doctype"})
+		}
+	}
+	for i := len(open) - 1; i >= 0; i-- {
+		out.WriteString("</" + open[i] + ">")
+	}
+	return out.String(), removed
+}
+
+func (s *Sanitizer) attributeAllowed(name string, allowed map[string]bool) bool {
+	if strings.HasPrefix(name, "on") || name == "style" || name == "srcdoc" {
+		return false
+	}
+	if allowed[name] || s.global[name] {
+		return true
+	}
+	for _, prefix := range s.prefixes {
+		if strings.HasPrefix(name, prefix) && len(name) > len(prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Sanitizer) filterAttributes(element string, attrs []html.Attribute, allowed map[string]bool, removed *removals) []html.Attribute {
+	var out []html.Attribute
+	seen := map[string]bool{}
+	for _, a := range attrs {
+		name := strings.ToLower(a.Key)
+		if a.Namespace != "" {
+			name = a.Namespace + ":" + name
+		}
+		if seen[name] || !s.attributeAllowed(name, allowed) {
+			removed.add(RemovedConstruct{Kind: "attribute", Element: element, Attribute: name})
+			continue
+		}
+		if urlAttributes[name] && !s.urlAllowed(a.Val) || element == "iframe" && name == "src" && !s.frameAllowed(a.Val) {
+			removed.add(RemovedConstruct{Kind: "url", Element: element, Attribute: name, Value: a.Val})
+			continue
+		}
+		seen[name] = true
+		out = append(out, html.Attribute{Key: name, Val: a.Val})
+	}
+	// Yeni sekmede açılan bağlantılar açan sayfaya erişemesin
+	if element == "a" && seen["target"] {
+		rel := ""
+		for i, a := range out {
+			if a.Key == "rel" {
+				rel = a.Val
+				out = append(out[:i], out[i+1:]...)
+				break
+			}
+		}
+		fields := strings.Fields(strings.ToLower(rel))
+		for _, required := range []string{"noopener", "noreferrer"} {
+			found := false
+			for _, f := range fields {
+				found = found || f == required
+			}
+			if !found {
+				fields = append(fields, required)
+			}
+		}
+		sort.Strings(fields)
+		out = append(out, html.Attribute{Key: "rel", Val: strings.Join(fields, " ")})
+	}
+	return out
+}
+
+// URL'nin şeması politikada var mı; şemasız (göreli) URL'ler kabul edilir.
+// Tarayıcılar şemadaki boşluk ve kontrol karakterlerini yok saydığı için önce bunlar atılır.
+func (s *Sanitizer) urlAllowed(raw string) bool {
+	cleaned := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, raw)
+	colon := strings.IndexByte(cleaned, ':')
+	if colon < 0 {
+		return true
+	}
+	if i := strings.IndexAny(cleaned, "/?#"); i >= 0 && i < colon {
+		return true
+	}
+	return s.schemes[strings.ToLower(cleaned[:colon])]
+}
+
+func hasAttribute(attrs []html.Attribute, name string) bool {
+	for _, a := range attrs {
+		if a.Key == name {
+			return true
+		}
+	}
+	return false
+}
+
+// iframe src'si yalnızca mutlak http(s) URL ve FrameHosts'taki bir host olabilir
+func (s *Sanitizer) frameAllowed(raw string) bool {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Scheme != "https" && u.Scheme != "http" {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, allowed := range s.frameHosts {
+		if host == allowed {
+			return true
+		}
+		if strings.HasPrefix(allowed, "*.") && strings.HasSuffix(host, allowed[1:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// ActionSanitizeReport: bir aksiyonun HTML alanından atılan yapılar
+type ActionSanitizeReport struct {
+	Index   int                `json:"index"`
+	Field   string             `json:"field"`
+	Removed []RemovedConstruct `json:"removed"`
+}
+
+func describeRemovals(removed []RemovedConstruct) string {
+	parts := make([]string, len(removed))
+	for i, c := range removed {
+		parts[i] = c.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ?strict=true: sanitize edilmesi gereken içerik düzeltilmek yerine reddedilir
+func strictSanitize(r *http.Request) bool {
+	return r.URL.Query().Get("strict") == "true"
+}
+
+// Kayıt yanıtı: mesaj ve sanitize raporu (hiçbir şey atılmadıysa boş liste)
+func writeSaveResult(w http.ResponseWriter, status int, message string, report []ActionSanitizeReport) {
+	if report == nil {
+		report = []ActionSanitizeReport{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{"message": message, "sanitized": report})
+}
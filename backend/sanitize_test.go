@@ -0,0 +1,227 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+// Bilinen XSS vektörleri (OWASP filter evasion cheat sheet ve mXSS örnekleri)
+var xssCorpus = []string{
+	`<script>alert(1)</script>`,
+	`<SCRIPT SRC=http://xss.example/xss.js></SCRIPT>`,
+	"<script\n>alert(1)</script\n>",
+	`<scr<script>ipt>alert(1)</script>`,
+	`<<script>alert(1)//<</script>`,
+	`<img src=x onerror=alert(1)>`,
+	`<img src="x" onerror="alert(1)">`,
+	"<img src=x` onerror=alert(1)>",
+	`<img """><script>alert(1)</script>">`,
+	`<IMG SRC="javascript:alert('XSS');">`,
+	`<IMG SRC=JaVaScRiPt:alert('XSS')>`,
+	`<IMG SRC=&#106;&#97;&#118;&#97;&#115;&#99;&#114;&#105;&#112;&#116;&#58;alert(1)>`,
+	`<IMG SRC=&#x6A&#x61&#x76&#x61&#x73&#x63&#x72&#x69&#x70&#x74&#x3A;alert(1)>`,
+	"<IMG SRC=\"jav\tascript:alert('XSS');\">",
+	`<IMG SRC="jav&#x09;ascript:alert('XSS');">`,
+	`<IMG SRC="jav&#x0A;ascript:alert('XSS');">`,
+	`<IMG SRC=" &#14;  javascript:alert('XSS');">`,
+	`<a href="javascript:alert(1)">x</a>`,
+	`<a href="  JAVASCRIPT:alert(1)">x</a>`,
+	`<a href="&#x6A;avascript:alert(1)">x</a>`,
+	`<a href="java&#0000script:alert(1)">x</a>`,
+	`<a href="vbscript:msgbox(1)">x</a>`,
+	`<a href="data:text/html;base64,PHNjcmlwdD5hbGVydCgxKTwvc2NyaXB0Pg==">x</a>`,
+	`<a onclick='alert(1)'>x</a>`,
+	`<a href="#" ONMOUSEOVER="alert(1)">x</a>`,
+	`<div onmouseover="alert(1)">x</div>`,
+	`<div style="background:url(javascript:alert(1))">x</div>`,
+	`<div style="width: expression(alert(1))">x</div>`,
+	`<iframe src="javascript:alert(1)"></iframe>`,
+	`<iframe srcdoc="<script>alert(1)</script>"></iframe>`,
+	`<object data="javascript:alert(1)"></object>`,
+	`<embed src="javascript:alert(1)">`,
+	`<svg onload=alert(1)>`,
+	`<svg><script>alert(1)</script></svg>`,
+	`<svg><a xlink:href="javascript:alert(1)"><text>x</text></a></svg>`,
+	`<math><mtext><table><mglyph><style><img src=x onerror=alert(1)>`,
+	`<body onload=alert(1)>`,
+	`<link rel=stylesheet href="javascript:alert(1)">`,
+	`<meta http-equiv="refresh" content="0;url=javascript:alert(1)">`,
+	`<base href="javascript:alert(1)//">`,
+	`<form action="javascript:alert(1)"><button>x</button></form>`,
+	`<button formaction="javascript:alert(1)">x</button>`,
+	`<input onfocus=alert(1) autofocus>`,
+	`<details open ontoggle=alert(1)>`,
+	`<video><source onerror=alert(1)></video>`,
+	`<img src=x:alert(alt) onerror=eval(src) alt=0>`,
+	`<!--<img src="--><img src=x onerror=alert(1)//">`,
+	`<noscript><p title="</noscript><img src=x onerror=alert(1)>">`,
+	`<style>@import 'javascript:alert(1)';</style>`,
+	`<template><script>alert(1)</script></template>`,
+	`<xmp><script>alert(1)</script></xmp>`,
+	`<textarea><script>alert(1)</script></textarea>`,
+	`<title><script>alert(1)</script></title>`,
+	`<isindex action=javascript:alert(1) type=image>`,
+	`<p title="&quot;><script>alert(1)</script>">x</p>`,
+	`<span data-x="1" onclick="alert(1)">x</span>`,
+}
+
+// Çıktı tekrar tokenize edilir; her element ve attribute politikaya uymalı
+func assertSafeHTML(t *testing.T, input, got string) {
+	t.Helper()
+	z := html.NewTokenizer(strings.NewReader(got))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return
+		}
+		tok := z.Token()
+		switch tt {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			allowed, ok := defaultSanitizer.elements[tok.Data]
+			if !ok {
+				t.Errorf("izinsiz element <%s> kaldı\ninput: %q\ngot:   %q", tok.Data, input, got)
+				continue
+			}
+			for _, a := range tok.Attr {
+				if !defaultSanitizer.attributeAllowed(a.Key, allowed) {
+					t.Errorf("izinsiz attribute %s kaldı\ninput: %q\ngot:   %q", a.Key, input, got)
+				}
+				if urlAttributes[a.Key] && !defaultSanitizer.urlAllowed(a.Val) {
+					t.Errorf("güvensiz URL %s=%q kaldı\ninput: %q\ngot:   %q", a.Key, a.Val, input, got)
+				}
+			}
+		case html.CommentToken, html.DoctypeToken:
+			t.Errorf("yorum/doctype kaldı\ninput: %q\ngot:   %q", input, got)
+		}
+	}
+}
+
+func TestSanitizeHTMLXSSCorpus(t *testing.T) {
+	for _, input := range xssCorpus {
+		got := sanitizeHTML(input)
+		assertSafeHTML(t, input, got)
+		// Metin içindeki etiketler kaçışlı olmalı
+		if strings.Contains(strings.ToLower(got), "<script") {
+			t.Errorf("<script kaldı\ninput: %q\ngot:   %q", input, got)
+		}
+		// Sanitize edilmiş çıktı tekrar temizlendiğinde değişmemeli
+		if again := sanitizeHTML(got); again != got {
+			t.Errorf("sanitize idempotent değil\ninput: %q\nfirst: %q\nagain: %q", input, got, again)
+		}
+	}
+}
+
+func TestSanitizeHTMLKeepsSafeMarkup(t *testing.T) {
+	cases := map[string]string{
+		`<header id='new-header'>Yeni Header</header>`:        `<header id="new-header">Yeni Header</header>`,
+		`<p class="x">a <b>b</b> <unknown>c</unknown></p>`:    `<p class="x">a <b>b</b> c</p>`,
+		`<a href="/docs?a=1&amp;b=2" data-track="x">d</a>`:    `<a href="/docs?a=1&amp;b=2" data-track="x">d</a>`,
+		`<a href="https://example.com" target="_blank">e</a>`: `<a href="https://example.com" target="_blank" rel="noopener noreferrer">e</a>`,
+		`<img src="https://cdn.example.com/a.png" alt="a">`:   `<img src="https://cdn.example.com/a.png" alt="a"/>`,
+		`<a href="mailto:info@example.com">m</a>`:             `<a href="mailto:info@example.com">m</a>`,
+		`<div><span>açık kalan`:                               `<div><span>açık kalan</span></div>`,
+		`</div>fazla kapanış`:                                 `fazla kapanış`,
+		`<ul><li>1</li><li>2</ul>`:                            `<ul><li>1</li><li>2</li></ul>`,
+		`x < y & z`:                                           `x &lt; y &amp; z`,
+	}
+	for input, want := range cases {
+		if got := sanitizeHTML(input); got != want {
+			t.Errorf("sanitizeHTML(%q)\n got: %q\nwant: %q", input, got, want)
+		}
+	}
+}
+
+func TestSanitizerCustomPolicy(t *testing.T) {
+	s := NewSanitizer(SanitizerPolicy{
+		ID:         "strict",
+		Elements:   map[string][]string{"b": nil, "a": {"href"}, "script": nil},
+		URLSchemes: []string{"https"},
+	})
+	got := s.Sanitize(`<p><b class="x">k</b><a href="http://e.com">l</a><a href="https://e.com">m</a><script>n</script></p>`)
+	want := `<b>k</b><a>l</a><a href="https://e.com">m</a>`
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSanitizeWithReport(t *testing.T) {
+	input := `<!-- x --><div onclick="a()" ONCLICK="b()"><script>c()</script><font>d</font>` +
+		`<a href="javascript:e()">f</a><a href="/ok" title="t">g</a></div>`
+	got, removed := defaultSanitizer.SanitizeWithReport(input)
+	if want := `<div>d<a>f</a><a href="/ok" title="t">g</a></div>`; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	want := []RemovedConstruct{
+		{Kind: "comment"},
+		{Kind: "attribute", Element: "div", Attribute: "onclick"},
+		{Kind: "element", Element: "script"},
+		{Kind: "tag", Element: "font"},
+		{Kind: "url", Element: "a", Attribute: "href", Value: "javascript:e()"},
+	}
+	if len(removed) != len(want) {
+		t.Fatalf("removed = %+v", removed)
+	}
+	for i := range want {
+		if removed[i] != want[i] {
+			t.Errorf("removed[%d] = %+v, want %+v", i, removed[i], want[i])
+		}
+	}
+
+	// Yalnızca yazım normalleştirmesi rapora girmez
+	if _, removed := defaultSanitizer.SanitizeWithReport(`<p class='x'>a &amp; b<br>`); len(removed) != 0 {
+		t.Errorf("normalization reported as removal: %+v", removed)
+	}
+}
+
+func TestSaveReturnsSanitizeReport(t *testing.T) {
+	useMemoryStore(t)
+	router := newRouter()
+
+	actions := `[{"type": "remove", "selector": ".ad"},
+		{"type": "replace", "selector": "#x", "newElement": "<div onclick=\"a()\">x<script>b()</script></div>"},
+		{"type": "insert", "position": "append", "target": "body", "element": "<b>temiz</b>"}]`
+	for _, tt := range []struct{ method, path, body string }{
+		{"POST", "/api/configuration", `{"id": "demo", "actions": ` + actions + `}`},
+		{"PUT", "/api/specific/demo?upsert=true", `{"actions": ` + actions + `}`},
+		{"POST", "/api/pages", `{"id": "demo", "actions": ` + actions + `}`},
+	} {
+		w := doRequest(router, tt.method, tt.path, tt.body, nil)
+		var resp struct {
+			Message   string                 `json:"message"`
+			Sanitized []ActionSanitizeReport `json:"sanitized"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || w.Code != http.StatusCreated {
+			t.Fatalf("%s %s: %d %s", tt.method, tt.path, w.Code, w.Body.String())
+		}
+		if len(resp.Sanitized) != 1 || resp.Sanitized[0].Index != 1 || resp.Sanitized[0].Field != "newElement" || len(resp.Sanitized[0].Removed) != 2 {
+			t.Errorf("%s %s: sanitized = %+v", tt.method, tt.path, resp.Sanitized)
+		}
+	}
+
+	// Temiz içerikte rapor boş liste
+	w := doRequest(router, "PUT", "/api/configuration/demo", `{"actions": [{"type": "remove", "selector": ".ad"}]}`, nil)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"sanitized":[]`) {
+		t.Errorf("clean PUT: %d %s", w.Code, w.Body.String())
+	}
+
+	// strict: içerik değiştirilmez, istek reddedilir
+	w = doRequest(router, "PUT", "/api/pages/demo?strict=true", `{"actions": `+actions+`}`, nil)
+	var resp struct {
+		Errors ValidationErrors `json:"errors"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusUnprocessableEntity || len(resp.Errors) != 1 ||
+		resp.Errors[0].Path != "actions[1].newElement" || resp.Errors[0].Code != codeUnsafeHTML {
+		t.Fatalf("strict PUT: %d %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(resp.Errors[0].Message, "onclick (<div>)") || !strings.Contains(resp.Errors[0].Message, "<script> (içeriğiyle)") {
+		t.Errorf("strict message = %q", resp.Errors[0].Message)
+	}
+	if w := doRequest(router, "PUT", "/api/pages/demo?strict=true", `{"actions": [{"type": "insert", "position": "append", "target": "body", "element": "<b>temiz</b>"}]}`, nil); w.Code != http.StatusOK {
+		t.Errorf("strict clean PUT: %d %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,469 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/gorilla/mux"
+)
+
+// Config türlerinin JSON Schema (2020-12) belgeleri. Şemalar Go tiplerinden (aksiyon struct'ları,
+// Condition, PagesConfig) ve aksiyonların check() kurallarından üretilir; aynı belge
+// GET /api/schema/{kind} ile sunulur ve gelen gövdeler ile aksiyon listeleri bununla doğrulanır.
+// Böylece belgelenen biçim ile sunucunun kabul ettiği biçim ayrışamaz.
+
+const schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema: bu projede kullanılan JSON Schema anahtar kelimeleri.
+// AdditionalProperties false ya da değerlerin uyması gereken *JSONSchema olabilir.
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	ID                   string                 `json:"$id,omitempty"`
+	Ref                  string                 `json:"$ref,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Const                string                 `json:"const,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	MinLength            int                    `json:"minLength,omitempty"`
+	MaxLength            int                    `json:"maxLength,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	ReadOnly             bool                   `json:"readOnly,omitempty"`
+	Not                  *JSONSchema            `json:"not,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties interface{}            `json:"additionalProperties,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	OneOf                []*JSONSchema          `json:"oneOf,omitempty"`
+	// OpenAPI'deki gibi: oneOf dalı bu özelliğin const değerine göre seçilir. JSON Schema'da
+	// karşılığı olmadığından x- uzantısı olarak sunulur; standart doğrulayıcılar oneOf'u kullanır.
+	Discriminator *SchemaDiscriminator   `json:"x-discriminator,omitempty"`
+	Defs          map[string]*JSONSchema `json:"$defs,omitempty"`
+}
+
+type SchemaDiscriminator struct {
+	PropertyName string `json:"propertyName"`
+}
+
+// Şemada yer alan aksiyon tipleri (newAction ile aynı set)
+var actionTypes = []string{"remove", "replace", "insert", "alter"}
+
+// Aksiyon alanlarının kabul ettiği sabit değerler; check() de aynı listeleri kullanır
+var actionEnums = map[string]map[string][]string{
+	"insert": {"position": insertPositions},
+}
+
+// Boş olmaması gereken metin alanları: yalnızca boşluktan oluşan değer de eksik sayılır
+const nonBlankPattern = `\S`
+
+// Go tipinin şeması. Actions ve *Condition ortak tanımlara ($defs) bağlanır.
+func schemaForType(t reflect.Type) *JSONSchema {
+	switch t {
+	case reflect.TypeOf(Actions(nil)):
+		return &JSONSchema{Ref: "#/$defs/actions"}
+	case reflect.TypeOf(&Condition{}):
+		return &JSONSchema{Ref: "#/$defs/condition"}
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return &JSONSchema{Type: "string"}
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case reflect.Int:
+		return &JSONSchema{Type: "integer"}
+	case reflect.Slice:
+		return &JSONSchema{Type: "array", Items: schemaForType(t.Elem())}
+	case reflect.Map:
+		if t.Elem().Kind() == reflect.String {
+			return &JSONSchema{Type: "object", AdditionalProperties: &JSONSchema{Type: "string"}}
+		}
+		return &JSONSchema{Type: "object"}
+	case reflect.Struct:
+		s := &JSONSchema{Type: "object", Properties: map[string]*JSONSchema{}}
+		var walk func(t reflect.Type)
+		walk = func(t reflect.Type) {
+			for i := 0; i < t.NumField(); i++ {
+				f := t.Field(i)
+				if f.Anonymous {
+					walk(f.Type)
+					continue
+				}
+				name := strings.Split(f.Tag.Get("json"), ",")[0]
+				if name != "" && name != "-" {
+					s.Properties[name] = schemaForType(f.Type)
+				}
+			}
+		}
+		walk(t)
+		return s
+	}
+	return &JSONSchema{}
+}
+
+// Boş bir aksiyonda check()'in "zorunlu alan" dediği alanlar; şemadaki required listesi buradan gelir
+func requiredActionFields(act Action) []string {
+	var out []string
+	for _, e := range act.check() {
+		if e.Code == codeRequired {
+			out = append(out, e.Field)
+		}
+	}
+	return out
+}
+
+func actionSchema(typ string) *JSONSchema {
+	act := newAction(typ)
+	s := schemaForType(reflect.TypeOf(act))
+	s.Title = typ + " aksiyonu"
+	s.Properties["type"] = &JSONSchema{Type: "string", Const: typ}
+	s.Required = append([]string{"type"}, requiredActionFields(act)...)
+	s.AdditionalProperties = false
+	for _, name := range s.Required[1:] {
+		s.Properties[name].MinLength = 1
+		s.Properties[name].Pattern = nonBlankPattern
+	}
+	for field, values := range actionEnums[typ] {
+		s.Properties[field].Enum = values
+	}
+	return s
+}
+
+// Tüm config türlerinin paylaştığı tanımlar: koşul bloğu ve aksiyon birleşimi
+var schemaDefs = buildSchemaDefs()
+
+func buildSchemaDefs() map[string]*JSONSchema {
+	condition := schemaForType(reflect.TypeOf(Condition{}))
+	condition.Title = "koşul"
+	condition.Description = "Aksiyonun uygulanması için sağlanması gereken koşullar (frontend checkCondition)"
+	condition.AdditionalProperties = false
+
+	action := &JSONSchema{Discriminator: &SchemaDiscriminator{PropertyName: "type"}}
+	defs := map[string]*JSONSchema{
+		"condition": condition,
+		"action":    action,
+		"actions":   {Type: "array", Items: &JSONSchema{Ref: "#/$defs/action"}},
+	}
+	for _, typ := range actionTypes {
+		defs["action_"+typ] = actionSchema(typ)
+		action.OneOf = append(action.OneOf, &JSONSchema{Ref: "#/$defs/action_" + typ})
+	}
+	return defs
+}
+
+// Tür başına kök şema. Genel/spesifik config'ler serbest biçimlidir ama bilinen alanları
+// PagesConfig'in bir alt kümesidir; onlarda actions da zorunludur.
+var configSchemas = map[ConfigKind]*JSONSchema{
+	KindGeneral:  configSchema(KindGeneral, "Genel config", "id", "actions"),
+	KindSpecific: configSchema(KindSpecific, "Spesifik config", "id", "actions"),
+	KindPages:    configSchema(KindPages, "Pages config", "id"),
+}
+
+// Türün kullanmadığı PagesConfig alanları: priority yalnız pages resolve sıralamasında,
+// datasource.pages yalnız pages'te, datasource.urls yalnız spesifik aramada ve pages'te anlamlıdır
+var configSchemaOmit = map[ConfigKind][]string{
+	KindGeneral:  {"priority", "datasource.pages", "datasource.urls", "validation_errors"},
+	KindSpecific: {"priority", "datasource.pages", "validation_errors"},
+}
+
+func configSchema(kind ConfigKind, title string, required ...string) *JSONSchema {
+	s := schemaForType(reflect.TypeOf(PagesConfig{}))
+	s.Schema = schemaDialect
+	s.ID = "/api/schema/" + string(kind)
+	s.Title = title
+	s.Required = required
+	for _, field := range configSchemaOmit[kind] {
+		if parent, name, ok := strings.Cut(field, "."); ok {
+			delete(s.Properties[parent].Properties, name)
+		} else {
+			delete(s.Properties, field)
+		}
+	}
+	// datasource'ta türün tanımadığı anahtar sessizce saklanmasın
+	s.Properties["datasource"].AdditionalProperties = false
+	id := &JSONSchema{Type: "string", MinLength: 1, MaxLength: maxConfigIDLength, Pattern: `^[A-Za-z0-9][A-Za-z0-9_-]*$`}
+	if kind == KindGeneral {
+		// validateConfigID ile aynı kural: bu önekler FileStore'da diğer türlerin dosya adlarıyla çakışır
+		id.Not = &JSONSchema{Type: "string", Pattern: `^(pages_|specific_|policy_)`,
+			Description: "pages_, specific_ ve policy_ önekleri ayrılmıştır"}
+	}
+	s.Properties["id"] = id
+	s.Properties["applied_sanitizer_policy"].ReadOnly = true
+	if p, ok := s.Properties["validation_errors"]; ok {
+		p.ReadOnly = true
+	}
+	extends := *id
+	extends.MinLength = 0
+	s.Properties["extends"] = &extends
+	omit := s.Properties["omit_actions"].Items
+	omit.Required = []string{"type"}
+	omit.AdditionalProperties = false
+	omit.Properties["type"].Enum = actionTypes
+	s.Defs = schemaDefs
+	return s
+}
+
+// Şemanın beklediği tip için kullanıcıya gösterilecek mesaj
+func schemaTypeMessage(s *JSONSchema) string {
+	switch s.Type {
+	case "string":
+		return "metin olmalı"
+	case "boolean":
+		return "true/false olmalı"
+	case "integer":
+		return "tam sayı olmalı"
+	case "array":
+		return "liste olmalı"
+	case "object":
+		if values, ok := s.AdditionalProperties.(*JSONSchema); ok && values.Type == "string" {
+			return "anahtar/metin eşlemesi olmalı"
+		}
+		return "nesne olmalı"
+	}
+	return "geçersiz değer"
+}
+
+// "a, b, c veya d olmalı"
+func enumMessage(values []string) string {
+	if len(values) == 1 {
+		return values[0] + " olmalı"
+	}
+	return strings.Join(values[:len(values)-1], ", ") + " veya " + values[len(values)-1] + " olmalı"
+}
+
+func schemaPath(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
+
+var schemaPatterns sync.Map // desen -> *regexp.Regexp
+
+func schemaPattern(p string) *regexp.Regexp {
+	if re, ok := schemaPatterns.Load(p); ok {
+		return re.(*regexp.Regexp)
+	}
+	re := regexp.MustCompile(p)
+	schemaPatterns.Store(p, re)
+	return re
+}
+
+// validateSchema, JSON/YAML'den serbest biçimli çözülmüş değeri şemaya göre denetler.
+// Nesnelerde anahtarlar alfabetik sırayla, ardından eksik zorunlu alanlar required sırasıyla raporlanır.
+func validateSchema(s *JSONSchema, value interface{}, path string) ValidationErrors {
+	var errs ValidationErrors
+	fail := func(path, code, msg string) {
+		errs = append(errs, ValidationError{Path: path, Code: code, Message: msg})
+	}
+	if s.Ref != "" {
+		return validateSchema(schemaDefs[strings.TrimPrefix(s.Ref, "#/$defs/")], value, path)
+	}
+	if s.Discriminator != nil {
+		return validateDiscriminated(s, value, path)
+	}
+	if s.Not != nil && len(validateSchema(s.Not, value, path)) == 0 {
+		fail(path, codeInvalidFormat, s.Not.Description)
+	}
+	switch s.Type {
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			fail(path, codeInvalidType, schemaTypeMessage(s))
+			break
+		}
+		// Boş ya da yalnızca boşluktan oluşan değer eksik alan sayılır
+		if (s.MinLength > 0 || s.Pattern != "") && strings.TrimSpace(str) == "" {
+			fail(path, codeRequired, "zorunlu alan")
+			break
+		}
+		if s.Const != "" && str != s.Const {
+			fail(path, codeInvalidEnum, s.Const+" olmalı")
+		}
+		if len(s.Enum) > 0 && !containsString(s.Enum, str) {
+			fail(path, codeInvalidEnum, enumMessage(s.Enum))
+		}
+		if s.MaxLength > 0 && utf8.RuneCountInString(str) > s.MaxLength {
+			fail(path, codeInvalidFormat, fmt.Sprintf("en fazla %d karakter olabilir", s.MaxLength))
+		}
+		if s.Pattern != "" && !schemaPattern(s.Pattern).MatchString(str) {
+			fail(path, codeInvalidFormat, "biçim geçersiz ("+s.Pattern+")")
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fail(path, codeInvalidType, schemaTypeMessage(s))
+		}
+	case "integer":
+		if !isInteger(value) {
+			fail(path, codeInvalidType, schemaTypeMessage(s))
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			fail(path, codeInvalidType, schemaTypeMessage(s))
+			break
+		}
+		if s.Items != nil {
+			for i, item := range items {
+				errs = append(errs, validateSchema(s.Items, item, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	case "object":
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			fail(path, codeInvalidType, schemaTypeMessage(s))
+			break
+		}
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if prop, ok := s.Properties[k]; ok {
+				if prop.ReadOnly {
+					// Sunucunun hesapladığı alan; GET yanıtı olduğu gibi geri gönderilebilsin diye yok sayılır
+					continue
+				}
+				errs = append(errs, validateSchema(prop, m[k], schemaPath(path, k))...)
+				continue
+			}
+			switch extra := s.AdditionalProperties.(type) {
+			case bool:
+				if !extra {
+					fail(schemaPath(path, k), codeUnknownField, "bilinmeyen alan (geçerli alanlar: "+strings.Join(schemaPropertyNames(s), ", ")+")")
+				}
+			case *JSONSchema:
+				errs = append(errs, validateSchema(extra, m[k], schemaPath(path, k))...)
+			}
+		}
+		for _, k := range s.Required {
+			if _, ok := m[k]; !ok {
+				fail(schemaPath(path, k), codeRequired, "zorunlu alan")
+			}
+		}
+	}
+	return errs
+}
+
+// oneOf dalını discriminator özelliğine göre seç ve yalnız o dalı doğrula.
+// Şemada tek discriminator aksiyon birleşimidir; eşleşmeyen değer bilinmeyen aksiyon tipidir.
+func validateDiscriminated(s *JSONSchema, value interface{}, path string) ValidationErrors {
+	prop := s.Discriminator.PropertyName
+	fail := func(code, msg string) ValidationErrors {
+		return ValidationErrors{{Path: schemaPath(path, prop), Code: code, Message: msg}}
+	}
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return ValidationErrors{{Path: path, Code: codeInvalidType, Message: "nesne olmalı"}}
+	}
+	raw, ok := m[prop]
+	if !ok {
+		return fail(codeRequired, "zorunlu alan")
+	}
+	typ, ok := raw.(string)
+	if !ok {
+		return fail(codeInvalidType, "metin olmalı")
+	}
+	for _, branch := range s.OneOf {
+		resolved := branch
+		if branch.Ref != "" {
+			resolved = schemaDefs[strings.TrimPrefix(branch.Ref, "#/$defs/")]
+		}
+		if resolved.Properties[prop].Const == typ {
+			return validateSchema(resolved, value, path)
+		}
+	}
+	return fail(codeUnknownActionType, fmt.Sprintf("bilinmeyen aksiyon tipi %q", typ))
+}
+
+func schemaPropertyNames(s *JSONSchema) []string {
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// JSON sayıları float64, YAML tam sayıları int olarak çözülür
+func isInteger(v interface{}) bool {
+	switch n := v.(type) {
+	case int, int64, uint64:
+		return true
+	case float64:
+		return n == float64(int64(n))
+	}
+	return false
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Gövdeyi türün şemasına göre doğrula. readOnly alanlar (applied_sanitizer_policy,
+// validation_errors) gövdeden atılır, saklanmaz.
+func checkBody(kind ConfigKind, body map[string]interface{}) ValidationErrors {
+	s := configSchemas[kind]
+	for name, prop := range s.Properties {
+		if prop.ReadOnly {
+			delete(body, name)
+		}
+	}
+	return validateSchema(s, body, "")
+}
+
+// İstek gövdesini şemaya göre doğrula; sorun varsa 422 yazar ve false döner
+func validateBody(w http.ResponseWriter, kind ConfigKind, body map[string]interface{}) bool {
+	if errs := checkBody(kind, body); len(errs) > 0 {
+		writeValidationErrors(w, errs)
+		return false
+	}
+	return true
+}
+
+// Pages gövdesini şemaya göre doğrulayıp PagesConfig'e çevir; sorun varsa 422 yazar ve false döner
+func decodePagesBody(w http.ResponseWriter, body map[string]interface{}) (*PagesConfig, bool) {
+	if !validateBody(w, KindPages, body) {
+		return nil, false
+	}
+	b, err := json.Marshal(body)
+	var cfg PagesConfig
+	if err == nil {
+		err = json.Unmarshal(b, &cfg)
+	}
+	if err != nil {
+		if !writeValidationError(w, err) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": "JSON parse hatası"}`))
+		}
+		return nil, false
+	}
+	return &cfg, true
+}
+
+// GET /api/schema/{kind}
+func handleGetSchema(w http.ResponseWriter, r *http.Request) {
+	s, ok := configSchemas[ConfigKind(mux.Vars(r)["kind"])]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error": "Şema bulunamadı"}`))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s)
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestSchemaEndpoint(t *testing.T) {
+	useMemoryStore(t)
+	router := newRouter()
+
+	for _, kind := range []ConfigKind{KindGeneral, KindSpecific, KindPages} {
+		w := doRequest(router, "GET", "/api/schema/"+string(kind), "", nil)
+		var s JSONSchema
+		if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &s) != nil {
+			t.Fatalf("GET schema %s: %d %s", kind, w.Code, w.Body.String())
+		}
+		if s.Schema != schemaDialect || s.Properties["actions"].Ref != "#/$defs/actions" || len(s.Defs["action"].OneOf) != len(actionTypes) {
+			t.Errorf("%s: unexpected schema: %s", kind, w.Body.String())
+		}
+		if wantActions := kind != KindPages; reflect.DeepEqual(s.Required, []string{"id", "actions"}) != wantActions {
+			t.Errorf("%s: required = %v", kind, s.Required)
+		}
+		insert := s.Defs["action_insert"]
+		if !reflect.DeepEqual(insert.Required, []string{"type", "target", "element", "position"}) ||
+			!reflect.DeepEqual(insert.Properties["position"].Enum, insertPositions) {
+			t.Errorf("%s: insert schema = %+v", kind, insert)
+		}
+		if s.Defs["action_alter"].Properties["regex"].Type != "boolean" || s.Defs["action_remove"].Properties["priority"].Type != "integer" {
+			t.Errorf("%s: alter/remove field types wrong", kind)
+		}
+		if s.Defs["condition"].AdditionalProperties != false || s.Defs["condition"].Properties["isLoggedIn"].Type != "boolean" {
+			t.Errorf("%s: condition schema = %+v", kind, s.Defs["condition"])
+		}
+	}
+	if w := doRequest(router, "GET", "/api/schema/policy", "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("unknown kind: %d", w.Code)
+	}
+}
+
+// Her tür yalnız kullandığı alanları tanımlar; genel id'lerde ayrılmış önekler şemada da yasaktır
+func TestSchemaPerKind(t *testing.T) {
+	useMemoryStore(t)
+	router := newRouter()
+
+	for _, tt := range []struct {
+		kind                 ConfigKind
+		priority             bool
+		datasource, idPrefix string
+	}{
+		{KindGeneral, false, "hosts", "^(pages_|specific_|policy_)"},
+		{KindSpecific, false, "hosts,urls", ""},
+		{KindPages, true, "hosts,pages,urls", ""},
+	} {
+		w := doRequest(router, "GET", "/api/schema/"+string(tt.kind), "", nil)
+		var raw map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &raw)
+		if _, ok := asMap(asMap(raw["$defs"])["action"])["discriminator"]; ok {
+			t.Errorf("%s: non-standard discriminator keyword served", tt.kind)
+		}
+		s := configSchemas[tt.kind]
+		if _, ok := s.Properties["priority"]; ok != tt.priority {
+			t.Errorf("%s: priority present = %v", tt.kind, ok)
+		}
+		ds := s.Properties["datasource"]
+		if got := strings.Join(schemaPropertyNames(ds), ","); got != tt.datasource || ds.AdditionalProperties != false {
+			t.Errorf("%s: datasource properties = %s", tt.kind, got)
+		}
+		var not string
+		if s.Properties["id"].Not != nil {
+			not = s.Properties["id"].Not.Pattern
+		}
+		if not != tt.idPrefix {
+			t.Errorf("%s: id not pattern = %q", tt.kind, not)
+		}
+	}
+	if s := configSchemas[KindGeneral].Defs["action"]; s.Discriminator == nil || s.Discriminator.PropertyName != "type" {
+		t.Errorf("x-discriminator missing: %+v", s)
+	}
+
+	// Şema ile validateConfigID aynı id'leri reddeder
+	for _, id := range []string{"pages_blog", "specific_x", "policy_default", "blog", "pages"} {
+		errs := validateSchema(configSchemas[KindGeneral], map[string]interface{}{"id": id, "actions": []interface{}{}}, "")
+		if rejected := len(errs) == 1 && errs[0].Path == "id" && errs[0].Code == codeInvalidFormat; rejected != (validateConfigID(KindGeneral, id) != nil) {
+			t.Errorf("%s: schema errors %v", id, errs)
+		}
+	}
+
+	for _, tt := range []struct {
+		method, url, body, path, code string
+	}{
+		{"POST", "/api/configuration", `{"id": "x", "extends": "pages_blog", "actions": []}`, "extends", codeInvalidFormat},
+		{"POST", "/api/configuration", `{"id": "x", "datasource": {"pages": {"post": "x.yaml"}}, "actions": []}`, "datasource.pages", codeUnknownField},
+		{"POST", "/api/specific", `{"id": "x", "datasource": {"pages": {"post": "x.yaml"}}, "actions": []}`, "datasource.pages", codeUnknownField},
+	} {
+		w := doRequest(router, tt.method, tt.url, tt.body, nil)
+		var res struct {
+			Errors []ValidationError `json:"errors"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &res)
+		if w.Code != http.StatusUnprocessableEntity || len(res.Errors) != 1 || res.Errors[0].Path != tt.path || res.Errors[0].Code != tt.code {
+			t.Errorf("%s %s: %d %s", tt.url, tt.body, w.Code, w.Body.String())
+		}
+	}
+	if w := doRequest(router, "POST", "/api/specific", `{"id": "x", "datasource": {"urls": {"/a": "a.yaml"}}, "actions": []}`, nil); w.Code != http.StatusCreated {
+		t.Errorf("specific urls: %d %s", w.Code, w.Body.String())
+	}
+}
+
+// Şemadaki aksiyon alanları decoder'ın kabul ettikleriyle birebir aynı olmalı
+func TestSchemaMatchesActionStructs(t *testing.T) {
+	for _, typ := range actionTypes {
+		act := newAction(typ)
+		if act == nil {
+			t.Fatalf("newAction(%q) = nil", typ)
+		}
+		var fields []string
+		for name := range actionFields(act) {
+			fields = append(fields, name)
+		}
+		sort.Strings(fields)
+		if got := schemaPropertyNames(schemaDefs["action_"+typ]); !reflect.DeepEqual(got, fields) {
+			t.Errorf("%s: schema properties %v, struct fields %v", typ, got, fields)
+		}
+	}
+}
+
+func TestBodiesValidatedAgainstSchema(t *testing.T) {
+	useMemoryStore(t)
+	router := newRouter()
+
+	for _, tt := range []struct {
+		method, url, body string
+		want              []ValidationError
+	}{
+		{"POST", "/api/pages", `{"id": "x", "name": 5, "sanitizer_policy": ["a"], "actions": [{"type": "remove", "selector": " "}]}`,
+			[]ValidationError{{Path: "actions[0].selector", Code: codeRequired}, {Path: "name", Code: codeInvalidType}, {Path: "sanitizer_policy", Code: codeInvalidType}}},
+		{"PUT", "/api/pages/x?upsert=true", `{"datasource": {"hosts": ["a.example.com"]}, "metadata": "x"}`,
+			[]ValidationError{{Path: "datasource.hosts", Code: codeInvalidType}, {Path: "metadata", Code: codeInvalidType}}},
+		{"POST", "/api/configuration", `{"id": "x", "actions": [{"type": "alter", "oldValue": "a", "newValue": "b", "condition": {"cookie": {"a": true}}}]}`,
+			[]ValidationError{{Path: "actions[0].condition.cookie.a", Code: codeInvalidType}}},
+		{"PUT", "/api/specific/x?upsert=true", `{"actions": [{"type": "insert", "target": "body", "element": "<b>x</b>", "position": ""}]}`,
+			[]ValidationError{{Path: "actions[0].position", Code: codeRequired}}},
+	} {
+		w := doRequest(router, tt.method, tt.url, tt.body, nil)
+		var res struct {
+			Errors []ValidationError `json:"errors"`
+		}
+		if w.Code != http.StatusUnprocessableEntity || json.Unmarshal(w.Body.Bytes(), &res) != nil || len(res.Errors) != len(tt.want) {
+			t.Errorf("%s %s: %d %s", tt.method, tt.url, w.Code, w.Body.String())
+			continue
+		}
+		for i, e := range res.Errors {
+			if e.Path != tt.want[i].Path || e.Code != tt.want[i].Code {
+				t.Errorf("%s %s: error %d = %+v, want %+v", tt.method, tt.url, i, e, tt.want[i])
+			}
+		}
+	}
+
+	// Şemaya uyan gövde kabul edilir
+	body := `{"id": "ok", "name": "Ok", "datasource": {"hosts": {"a.example.com": "a.yaml"}}, "metadata": {"v": 1},
+		"actions": [{"type": "insert", "position": "append", "target": "body", "element": "<b>x</b>", "priority": 2,
+		"condition": {"isLoggedIn": true, "queryParam": {"ref": "x"}}}]}`
+	if w := doRequest(router, "POST", "/api/pages", body, nil); w.Code != http.StatusCreated {
+		t.Errorf("valid body: %d %s", w.Code, w.Body.String())
+	}
+}
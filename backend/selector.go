@@ -0,0 +1,842 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// CSS Selectors Level 3/4 ayrıştırıcısı. Tarayıcının querySelectorAll ile kabul
+// edeceği söz dizimini doğrular ve selector'ı bir AST olarak döndürür.
+
+// Kötü niyetli girdilere karşı sınırlar
+const (
+	maxSelectorLength = 1024
+	maxSelectorDepth  = 16
+)
+
+// SelectorList: virgülle ayrılmış selector'lar (".a, .b")
+type SelectorList []*ComplexSelector
+
+// Combinator: iki compound selector arasındaki ilişki
+type Combinator string
+
+const (
+	CombinatorDescendant        Combinator = " "
+	CombinatorChild             Combinator = ">"
+	CombinatorNextSibling       Combinator = "+"
+	CombinatorSubsequentSibling Combinator = "~"
+)
+
+// ComplexSelector: birleştiricilerle bağlanmış compound selector'lar ("nav > ul li")
+type ComplexSelector struct {
+	// Yalnızca :has() içindeki göreli selector'larda dolu olur (ör. ":has(> img)")
+	Leading   Combinator
+	Compounds []*CompoundSelector
+	// Combinators[i], Compounds[i] ile Compounds[i+1] arasındaki birleştiricidir
+	Combinators []Combinator
+}
+
+// CompoundSelector: aralarında boşluk olmayan basit selector'lar ("a.btn[href]:hover")
+type CompoundSelector struct {
+	Simple []SimpleSelector
+}
+
+// SimpleSelector: *TypeSelector, *IDSelector, *ClassSelector, *AttributeSelector,
+// *PseudoClass veya *PseudoElement
+type SimpleSelector interface {
+	Specificity() Specificity
+}
+
+// Element adı veya "*"; Namespace nil ise namespace belirtilmemiştir
+type TypeSelector struct {
+	Namespace *string
+	Name      string
+}
+
+type IDSelector struct {
+	Name string
+}
+
+type ClassSelector struct {
+	Name string
+}
+
+// [name], [name=value], [name^="value" i] ...
+type AttributeSelector struct {
+	Namespace *string
+	Name      string
+	// "", "=", "~=", "|=", "^=", "$=" veya "*="
+	Operator string
+	Value    string
+	// "i" veya "s" (büyük/küçük harf duyarlılığı), yoksa boş
+	Modifier string
+}
+
+// :hover, :not(.a), :nth-child(2n+1 of .item), :lang(tr) ...
+type PseudoClass struct {
+	Name string
+	// :not/:is/:where/:has argümanları ve :nth-child(... of S) içindeki S
+	Selectors SelectorList
+	// :nth-* ifadeleri
+	Nth *NthExpr
+	// :lang ve :dir argümanları
+	Args []string
+}
+
+// ::before, ::part(label), ::slotted(span) ...
+type PseudoElement struct {
+	Name string
+	// ::part argümanları
+	Args []string
+	// ::slotted argümanı
+	Selectors SelectorList
+	// Tek iki nokta ile yazılmış eski biçim (:before)
+	Legacy bool
+}
+
+// An+B ifadesi
+type NthExpr struct {
+	A, B int
+}
+
+// Specificity: (id, class/attribute/pseudo-class, type/pseudo-element)
+type Specificity [3]int
+
+func (s Specificity) add(o Specificity) Specificity {
+	return Specificity{s[0] + o[0], s[1] + o[1], s[2] + o[2]}
+}
+
+// Less, s'nin o'dan daha az spesifik olup olmadığını söyler
+func (s Specificity) Less(o Specificity) bool {
+	for i := range s {
+		if s[i] != o[i] {
+			return s[i] < o[i]
+		}
+	}
+	return false
+}
+
+func (t *TypeSelector) Specificity() Specificity {
+	if t.Name == "*" {
+		return Specificity{}
+	}
+	return Specificity{0, 0, 1}
+}
+
+func (s *IDSelector) Specificity() Specificity        { return Specificity{1, 0, 0} }
+func (s *ClassSelector) Specificity() Specificity     { return Specificity{0, 1, 0} }
+func (s *AttributeSelector) Specificity() Specificity { return Specificity{0, 1, 0} }
+
+func (p *PseudoClass) Specificity() Specificity {
+	switch p.Name {
+	case "where":
+		return Specificity{}
+	case "is", "not", "has":
+		return p.Selectors.Specificity()
+	case "nth-child", "nth-last-child":
+		return Specificity{0, 1, 0}.add(p.Selectors.Specificity())
+	}
+	return Specificity{0, 1, 0}
+}
+
+func (p *PseudoElement) Specificity() Specificity {
+	return Specificity{0, 0, 1}.add(p.Selectors.Specificity())
+}
+
+// Pseudo-element içeren compound selector'ı sonlandırır; ardından birleştirici gelemez
+func (c *CompoundSelector) hasPseudoElement() bool {
+	for _, simple := range c.Simple {
+		if _, ok := simple.(*PseudoElement); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CompoundSelector) Specificity() Specificity {
+	var s Specificity
+	for _, simple := range c.Simple {
+		s = s.add(simple.Specificity())
+	}
+	return s
+}
+
+func (c *ComplexSelector) Specificity() Specificity {
+	var s Specificity
+	for _, compound := range c.Compounds {
+		s = s.add(compound.Specificity())
+	}
+	return s
+}
+
+// Listedeki en spesifik selector'ın değeri (boş listede sıfır)
+func (l SelectorList) Specificity() Specificity {
+	var max Specificity
+	for _, c := range l {
+		if s := c.Specificity(); max.Less(s) {
+			max = s
+		}
+	}
+	return max
+}
+
+// SelectorError: ayrıştırma hatası; Pos bayt cinsinden konumdur
+type SelectorError struct {
+	Pos int
+	Msg string
+}
+
+func (e *SelectorError) Error() string {
+	return fmt.Sprintf("%d. karakterde %s", e.Pos+1, e.Msg)
+}
+
+// Argümansız pseudo-class'lar
+var simplePseudoClasses = map[string]bool{
+	"root": true, "empty": true, "scope": true, "defined": true,
+	"first-child": true, "last-child": true, "only-child": true,
+	"first-of-type": true, "last-of-type": true, "only-of-type": true,
+	"link": true, "visited": true, "any-link": true, "local-link": true, "target": true,
+	"hover": true, "active": true, "focus": true, "focus-within": true, "focus-visible": true,
+	"enabled": true, "disabled": true, "checked": true, "indeterminate": true, "default": true,
+	"required": true, "optional": true, "valid": true, "invalid": true, "user-valid": true, "user-invalid": true,
+	"in-range": true, "out-of-range": true, "read-only": true, "read-write": true,
+	"placeholder-shown": true, "autofill": true, "blank": true,
+	"fullscreen": true, "modal": true, "popover-open": true, "open": true, "closed": true,
+	"playing": true, "paused": true, "muted": true,
+}
+
+// Argümansız pseudo-element'ler; ilk dördü tek iki nokta ile de yazılabilir
+var simplePseudoElements = map[string]bool{
+	"before": true, "after": true, "first-line": true, "first-letter": true,
+	"marker": true, "placeholder": true, "selection": true, "backdrop": true,
+	"file-selector-button": true, "target-text": true, "spelling-error": true, "grammar-error": true,
+}
+
+// ParseSelector, bir selector listesini ayrıştırır
+func ParseSelector(sel string) (SelectorList, error) {
+	if len(sel) > maxSelectorLength {
+		return nil, &SelectorError{Pos: maxSelectorLength, Msg: fmt.Sprintf("selector en fazla %d karakter olabilir", maxSelectorLength)}
+	}
+	if !utf8.ValidString(sel) {
+		return nil, &SelectorError{Msg: "geçersiz UTF-8"}
+	}
+	p := &selectorParser{s: sel}
+	list, err := p.parseList(false)
+	if err != nil {
+		return nil, err
+	}
+	if !p.eof() {
+		return nil, p.errorf("beklenmeyen %q", p.peekRune())
+	}
+	return list, nil
+}
+
+type selectorParser struct {
+	s     string
+	pos   int
+	depth int
+}
+
+func (p *selectorParser) errorf(format string, args ...interface{}) error {
+	return &SelectorError{Pos: p.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *selectorParser) eof() bool { return p.pos >= len(p.s) }
+
+func (p *selectorParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+func (p *selectorParser) peekAt(n int) byte {
+	if p.pos+n >= len(p.s) {
+		return 0
+	}
+	return p.s[p.pos+n]
+}
+
+func (p *selectorParser) peekRune() rune {
+	r, _ := utf8.DecodeRuneInString(p.s[p.pos:])
+	return r
+}
+
+func isSelectorSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+// Boşlukları atlar; en az bir boşluk varsa true döner
+func (p *selectorParser) skipSpace() bool {
+	start := p.pos
+	for !p.eof() && isSelectorSpace(p.peek()) {
+		p.pos++
+	}
+	return p.pos > start
+}
+
+func (p *selectorParser) expect(c byte) error {
+	if p.peek() != c {
+		if p.eof() {
+			return p.errorf("%q bekleniyor, selector bitti", c)
+		}
+		return p.errorf("%q bekleniyor, %q bulundu", c, p.peekRune())
+	}
+	p.pos++
+	return nil
+}
+
+// relative: :has() içindeki gibi baştaki birleştiriciye izin verilir
+func (p *selectorParser) parseList(relative bool) (SelectorList, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxSelectorDepth {
+		return nil, p.errorf("selector çok derin iç içe")
+	}
+	var list SelectorList
+	for {
+		p.skipSpace()
+		c, err := p.parseComplex(relative)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, c)
+		p.skipSpace()
+		if p.peek() != ',' {
+			return list, nil
+		}
+		p.pos++
+	}
+}
+
+func combinatorOf(c byte) (Combinator, bool) {
+	switch c {
+	case '>':
+		return CombinatorChild, true
+	case '+':
+		return CombinatorNextSibling, true
+	case '~':
+		return CombinatorSubsequentSibling, true
+	}
+	return "", false
+}
+
+func (p *selectorParser) parseComplex(relative bool) (*ComplexSelector, error) {
+	c := &ComplexSelector{}
+	if comb, ok := combinatorOf(p.peek()); ok {
+		if !relative {
+			return nil, p.errorf("selector %q ile başlayamaz", string(comb))
+		}
+		c.Leading = comb
+		p.pos++
+		p.skipSpace()
+	}
+	compound, err := p.parseCompound()
+	if err != nil {
+		return nil, err
+	}
+	if compound == nil {
+		return nil, p.selectorExpected()
+	}
+	c.Compounds = append(c.Compounds, compound)
+	for {
+		sawSpace := p.skipSpace()
+		comb, ok := combinatorOf(p.peek())
+		if ok {
+			p.pos++
+			p.skipSpace()
+		} else if sawSpace && !p.eof() && p.peek() != ',' && p.peek() != ')' {
+			comb = CombinatorDescendant
+		} else {
+			return c, nil
+		}
+		if c.Compounds[len(c.Compounds)-1].hasPseudoElement() {
+			return nil, p.errorf("pseudo-element'ten sonra birleştirici gelemez")
+		}
+		compound, err := p.parseCompound()
+		if err != nil {
+			return nil, err
+		}
+		if compound == nil {
+			return nil, p.selectorExpected()
+		}
+		c.Combinators = append(c.Combinators, comb)
+		c.Compounds = append(c.Compounds, compound)
+	}
+}
+
+func (p *selectorParser) selectorExpected() error {
+	if p.eof() {
+		return p.errorf("selector bekleniyor, selector bitti")
+	}
+	return p.errorf("selector bekleniyor, %q bulundu", p.peekRune())
+}
+
+// Compound selector; hiçbir basit selector yoksa nil döner
+func (p *selectorParser) parseCompound() (*CompoundSelector, error) {
+	c := &CompoundSelector{}
+	if t, err := p.parseTypeSelector(); err != nil {
+		return nil, err
+	} else if t != nil {
+		c.Simple = append(c.Simple, t)
+	}
+	afterPseudoElement := false
+	for !p.eof() {
+		var simple SimpleSelector
+		var err error
+		start := p.pos
+		switch p.peek() {
+		case '#':
+			p.pos++
+			var name string
+			if name, err = p.ident("id"); err == nil {
+				simple = &IDSelector{Name: name}
+			}
+		case '.':
+			p.pos++
+			var name string
+			if name, err = p.ident("class adı"); err == nil {
+				simple = &ClassSelector{Name: name}
+			}
+		case '[':
+			simple, err = p.parseAttribute()
+		case ':':
+			if p.peekAt(1) == ':' {
+				if afterPseudoElement {
+					return nil, p.errorf("bir compound selector'da tek pseudo-element olabilir")
+				}
+				simple, err = p.parsePseudoElement()
+			} else {
+				simple, err = p.parsePseudoClass()
+			}
+		default:
+			if len(c.Simple) == 0 {
+				return nil, nil
+			}
+			return c, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := simple.(*PseudoElement); ok {
+			// :not(), :is(), :has(), ::slotted() gibi argümanlarda pseudo-element geçersizdir
+			if p.depth > 1 {
+				p.pos = start
+				return nil, p.errorf("pseudo-element selector argümanında kullanılamaz")
+			}
+			afterPseudoElement = true
+		} else if afterPseudoElement {
+			if _, ok := simple.(*PseudoClass); !ok {
+				return nil, p.errorf("pseudo-element'ten sonra yalnızca pseudo-class gelebilir")
+			}
+		}
+		c.Simple = append(c.Simple, simple)
+	}
+	if len(c.Simple) == 0 {
+		return nil, nil
+	}
+	return c, nil
+}
+
+// [ns|]name veya [ns|]*; "|" sonrası "=" ise attribute operatörüdür
+func (p *selectorParser) parseQualifiedName(allowStar bool, what string) (*string, string, error) {
+	nameOrStar := func() (string, error) {
+		if allowStar && p.peek() == '*' {
+			p.pos++
+			return "*", nil
+		}
+		return p.ident(what)
+	}
+	if p.peek() == '|' && p.peekAt(1) != '=' && p.peekAt(1) != '|' {
+		p.pos++
+		empty := ""
+		name, err := nameOrStar()
+		return &empty, name, err
+	}
+	var first string
+	if p.peek() == '*' {
+		p.pos++
+		first = "*"
+	} else {
+		var err error
+		if first, err = p.ident(what); err != nil {
+			return nil, "", err
+		}
+	}
+	if p.peek() == '|' && p.peekAt(1) != '=' && p.peekAt(1) != '|' {
+		p.pos++
+		ns := first
+		name, err := nameOrStar()
+		return &ns, name, err
+	}
+	if first == "*" && !allowStar {
+		return nil, "", p.errorf("%s bekleniyor", what)
+	}
+	return nil, first, nil
+}
+
+func (p *selectorParser) parseTypeSelector() (*TypeSelector, error) {
+	c := p.peek()
+	if c != '*' && c != '|' && !p.atIdentStart() {
+		return nil, nil
+	}
+	ns, name, err := p.parseQualifiedName(true, "element adı")
+	if err != nil {
+		return nil, err
+	}
+	return &TypeSelector{Namespace: ns, Name: name}, nil
+}
+
+var attributeOperators = []string{"~=", "|=", "^=", "$=", "*=", "="}
+
+func (p *selectorParser) parseAttribute() (*AttributeSelector, error) {
+	p.pos++ // [
+	p.skipSpace()
+	ns, name, err := p.parseQualifiedName(false, "attribute adı")
+	if err != nil {
+		return nil, err
+	}
+	attr := &AttributeSelector{Namespace: ns, Name: name}
+	p.skipSpace()
+	if p.peek() == ']' {
+		p.pos++
+		return attr, nil
+	}
+	for _, op := range attributeOperators {
+		if strings.HasPrefix(p.s[p.pos:], op) {
+			attr.Operator = op
+			p.pos += len(op)
+			break
+		}
+	}
+	if attr.Operator == "" {
+		return nil, p.errorf("attribute operatörü veya ']' bekleniyor")
+	}
+	p.skipSpace()
+	if c := p.peek(); c == '"' || c == '\'' {
+		attr.Value, err = p.quoted()
+	} else {
+		attr.Value, err = p.ident("attribute değeri")
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.atIdentStart() {
+		mod, err := p.ident("modifier")
+		if err != nil {
+			return nil, err
+		}
+		mod = strings.ToLower(mod)
+		if mod != "i" && mod != "s" {
+			return nil, p.errorf("bilinmeyen attribute modifier %q", mod)
+		}
+		attr.Modifier = mod
+		p.skipSpace()
+	}
+	if err := p.expect(']'); err != nil {
+		return nil, err
+	}
+	return attr, nil
+}
+
+func (p *selectorParser) parsePseudoClass() (SimpleSelector, error) {
+	p.pos++ // :
+	start := p.pos
+	name, err := p.ident("pseudo-class adı")
+	if err != nil {
+		return nil, err
+	}
+	name = strings.ToLower(name)
+	if p.peek() != '(' {
+		switch {
+		case simplePseudoClasses[name]:
+			return &PseudoClass{Name: name}, nil
+		case name == "before" || name == "after" || name == "first-line" || name == "first-letter":
+			return &PseudoElement{Name: name, Legacy: true}, nil
+		}
+		p.pos = start
+		return nil, p.errorf("bilinmeyen pseudo-class :%s", name)
+	}
+	p.pos++ // (
+	p.skipSpace()
+	pc := &PseudoClass{Name: name}
+	switch name {
+	case "not", "is", "where":
+		pc.Selectors, err = p.parseList(false)
+	case "has":
+		pc.Selectors, err = p.parseList(true)
+	case "nth-child", "nth-last-child", "nth-of-type", "nth-last-of-type":
+		pc.Nth, pc.Selectors, err = p.parseNth(name == "nth-child" || name == "nth-last-child")
+	case "lang":
+		pc.Args, err = p.parseArgs(true)
+	case "dir":
+		var dir string
+		if dir, err = p.ident("ltr veya rtl"); err == nil {
+			dir = strings.ToLower(dir)
+			if dir != "ltr" && dir != "rtl" {
+				err = p.errorf(":dir() yalnızca ltr veya rtl alabilir")
+			}
+			pc.Args = []string{dir}
+		}
+	default:
+		p.pos = start
+		return nil, p.errorf("bilinmeyen pseudo-class :%s()", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if err := p.expect(')'); err != nil {
+		return nil, err
+	}
+	return pc, nil
+}
+
+func (p *selectorParser) parsePseudoElement() (*PseudoElement, error) {
+	p.pos += 2 // ::
+	start := p.pos
+	name, err := p.ident("pseudo-element adı")
+	if err != nil {
+		return nil, err
+	}
+	name = strings.ToLower(name)
+	if p.peek() != '(' {
+		if !simplePseudoElements[name] {
+			p.pos = start
+			return nil, p.errorf("bilinmeyen pseudo-element ::%s", name)
+		}
+		return &PseudoElement{Name: name}, nil
+	}
+	p.pos++ // (
+	p.skipSpace()
+	pe := &PseudoElement{Name: name}
+	switch name {
+	case "part":
+		pe.Args, err = p.parseArgs(false)
+	case "slotted":
+		var compound *CompoundSelector
+		p.depth++
+		compound, err = p.parseCompound()
+		p.depth--
+		if err == nil {
+			if compound == nil {
+				err = p.selectorExpected()
+			} else {
+				pe.Selectors = SelectorList{{Compounds: []*CompoundSelector{compound}}}
+			}
+		}
+	default:
+		p.pos = start
+		return nil, p.errorf("bilinmeyen pseudo-element ::%s()", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if err := p.expect(')'); err != nil {
+		return nil, err
+	}
+	return pe, nil
+}
+
+// :lang(tr, "en-US") veya ::part(label icon) argümanları
+func (p *selectorParser) parseArgs(commaSeparated bool) ([]string, error) {
+	var args []string
+	for {
+		var arg string
+		var err error
+		if c := p.peek(); commaSeparated && (c == '"' || c == '\'') {
+			arg, err = p.quoted()
+		} else {
+			arg, err = p.ident("argüman")
+		}
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		sawSpace := p.skipSpace()
+		switch {
+		case commaSeparated && p.peek() == ',':
+			p.pos++
+			p.skipSpace()
+		case !commaSeparated && sawSpace && p.peek() != ')':
+		default:
+			return args, nil
+		}
+	}
+}
+
+var nthPattern = regexp.MustCompile(`^(?i:(even|odd)|([+-]?\d+)|([+-]?\d*)n(?:\s*([+-])\s*(\d+))?)$`)
+
+// An+B ifadesi ve (izin veriliyorsa) "of S" kısmı
+func (p *selectorParser) parseNth(allowOf bool) (*NthExpr, SelectorList, error) {
+	start := p.pos
+	end := p.pos
+	ofAt := -1
+	for end < len(p.s) && p.s[end] != ')' {
+		if end > start && isSelectorSpace(p.s[end-1]) && strings.HasPrefix(strings.ToLower(p.s[end:]), "of") &&
+			end+2 < len(p.s) && isSelectorSpace(p.s[end+2]) {
+			ofAt = end
+			break
+		}
+		end++
+	}
+	expr := strings.TrimSpace(p.s[start:end])
+	m := nthPattern.FindStringSubmatch(expr)
+	if m == nil {
+		return nil, nil, p.errorf("geçersiz An+B ifadesi %q", expr)
+	}
+	nth := &NthExpr{}
+	switch {
+	case m[1] != "":
+		nth.A = 2
+		if strings.EqualFold(m[1], "odd") {
+			nth.B = 1
+		}
+	case m[2] != "":
+		nth.B, _ = strconv.Atoi(m[2])
+	default:
+		switch m[3] {
+		case "", "+":
+			nth.A = 1
+		case "-":
+			nth.A = -1
+		default:
+			nth.A, _ = strconv.Atoi(m[3])
+		}
+		if m[5] != "" {
+			nth.B, _ = strconv.Atoi(m[5])
+			if m[4] == "-" {
+				nth.B = -nth.B
+			}
+		}
+	}
+	p.pos = end
+	if ofAt < 0 {
+		return nth, nil, nil
+	}
+	if !allowOf {
+		return nil, nil, p.errorf("\"of\" yalnızca :nth-child ve :nth-last-child içinde kullanılabilir")
+	}
+	p.pos += 2
+	list, err := p.parseList(false)
+	return nth, list, err
+}
+
+func isNameStart(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c >= 0x80
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || c >= '0' && c <= '9' || c == '-'
+}
+
+func (p *selectorParser) validEscapeAt(i int) bool {
+	return i+1 < len(p.s) && p.s[i] == '\\' && p.s[i+1] != '\n' && p.s[i+1] != '\r' && p.s[i+1] != '\f'
+}
+
+// Sıradaki karakterler bir identifier başlatıyor mu
+func (p *selectorParser) atIdentStart() bool {
+	i := p.pos
+	if i < len(p.s) && p.s[i] == '-' {
+		i++
+		if i < len(p.s) && p.s[i] == '-' {
+			return true
+		}
+	}
+	return i < len(p.s) && (isNameStart(p.s[i]) || p.validEscapeAt(i))
+}
+
+// CSS identifier (kaçış dizileri çözülmüş olarak)
+func (p *selectorParser) ident(what string) (string, error) {
+	if !p.atIdentStart() {
+		if p.eof() {
+			return "", p.errorf("%s bekleniyor, selector bitti", what)
+		}
+		return "", p.errorf("%s bekleniyor, %q bulundu", what, p.peekRune())
+	}
+	var b strings.Builder
+	for !p.eof() {
+		c := p.peek()
+		switch {
+		case isNameChar(c):
+			b.WriteByte(c)
+			p.pos++
+		case p.validEscapeAt(p.pos):
+			b.WriteRune(p.escape())
+		default:
+			return b.String(), nil
+		}
+	}
+	return b.String(), nil
+}
+
+// Ters bölü kaçışı: 1-6 hex rakam (+ tek boşluk) veya tek karakter
+func (p *selectorParser) escape() rune {
+	p.pos++ // \
+	hexEnd := p.pos
+	for hexEnd < len(p.s) && hexEnd-p.pos < 6 && isHexDigit(p.s[hexEnd]) {
+		hexEnd++
+	}
+	if hexEnd > p.pos {
+		n, _ := strconv.ParseUint(p.s[p.pos:hexEnd], 16, 32)
+		p.pos = hexEnd
+		if !p.eof() && isSelectorSpace(p.peek()) {
+			p.pos++
+		}
+		if n == 0 || n > utf8.MaxRune || n >= 0xD800 && n <= 0xDFFF {
+			return utf8.RuneError
+		}
+		return rune(n)
+	}
+	r, size := utf8.DecodeRuneInString(p.s[p.pos:])
+	p.pos += size
+	return r
+}
+
+func isHexDigit(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+// Tek veya çift tırnaklı metin
+func (p *selectorParser) quoted() (string, error) {
+	quote := p.peek()
+	start := p.pos
+	p.pos++
+	var b strings.Builder
+	for !p.eof() {
+		c := p.peek()
+		switch {
+		case c == quote:
+			p.pos++
+			return b.String(), nil
+		case c == '\n' || c == '\r' || c == '\f':
+			return "", p.errorf("tırnak içinde satır sonu")
+		case c == '\\':
+			if p.pos+1 < len(p.s) && (p.s[p.pos+1] == '\n') {
+				p.pos += 2
+				continue
+			}
+			if !p.validEscapeAt(p.pos) {
+				p.pos++
+				continue
+			}
+			b.WriteRune(p.escape())
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+	p.pos = start
+	return "", p.errorf("kapanmamış tırnak")
+}
+
+// Basit CSS selector validasyonu (tam ayrıştırma ile)
+func isValidSelector(sel string) bool {
+	_, err := ParseSelector(sel)
+	return err == nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseSelectorValid(t *testing.T) {
+	valid := []string{
+		"*", "div", ".ad", "#main", "a.btn.primary", "span#id.class",
+		".advertisement, .popup",
+		"nav > ul li + li ~ li",
+		"ul>li", "  .a  ", ".a,.b,.c",
+		"[data-x='1']", `[href^="https://" i]`, "[lang|=en]", "[class~=ad]", "[data-id]", "[ data-id = x s ]",
+		"a[href$='.pdf']", "img[src*=banner]",
+		"svg|rect", "*|*", "|p", "[xlink|href]",
+		":root", "li:first-child", "a:hover", "input:checked + label",
+		":not(.a)", ":not(.a, #b > c)", "div:not(:has(> img))", ":is(h1, h2):where(.x)",
+		"li:nth-child(2n+1)", "li:nth-child(odd)", "li:NTH-CHILD(EVEN)", "li:nth-child( -n + 3 )",
+		"li:nth-last-child(2)", "p:nth-of-type(3n)", "li:nth-child(2n of .item)",
+		":lang(tr)", `:lang("en-US", de)`, ":dir(rtl)",
+		"p::before", "p:before", "p::first-line", "a::after:hover", "::part(label icon)", "::slotted(span.x)", "p::before, a::after",
+		`.\31 23`, `#foo\:bar`, ".çiçek", ".-private", ".--custom", "._x",
+		"div\n>\tp",
+	}
+	for _, sel := range valid {
+		if _, err := ParseSelector(sel); err != nil {
+			t.Errorf("ParseSelector(%q) = %v, want valid", sel, err)
+		}
+	}
+}
+
+func TestParseSelectorInvalid(t *testing.T) {
+	invalid := []string{
+		"", "   ", "]]==", "<script>", "alert()", "div;", ".", "#", "#1abc", ".1abc", ".-1",
+		"a >", "> a", "a,", ",a", "a,,b", "a > > b", "a + ~ b",
+		"[", "[x", "[x=]", "[x==y]", "[x=y z]", "[=y]", "['x']",
+		"[x='unterminated]", ":hovr", ":not", ":not()", ":not(.a", ":foo(x)",
+		"li:nth-child()", "li:nth-child(2n+)", "li:nth-child(- n)", "li:nth-child(x)",
+		"p:nth-of-type(2n of .a)", ":dir(up)", "::befor", "p::before::after", "p::before.x",
+		"div::after .x", "a::before > b", "p:before + a", "p::before, a::after b",
+		":not(::before)", ":is(p::after)", ":where(:before)", "div:has(> ::marker)", "li:nth-child(2n of ::before)", "::slotted(::before)",
+		"a b)", "a*", "div.class#id#", "a || b", strings.Repeat(":not(", 20) + "a" + strings.Repeat(")", 20),
+		strings.Repeat("a", maxSelectorLength+1),
+	}
+	for _, sel := range invalid {
+		_, err := ParseSelector(sel)
+		var serr *SelectorError
+		if !errors.As(err, &serr) {
+			t.Errorf("ParseSelector(%q) = %v, want SelectorError", sel, err)
+		}
+	}
+}
+
+func TestParseSelectorAST(t *testing.T) {
+	list, err := ParseSelector(`nav > a.btn[href^="https"]:not(.ghost), #x`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("list len = %d", len(list))
+	}
+	c := list[0]
+	if len(c.Compounds) != 2 || len(c.Combinators) != 1 || c.Combinators[0] != CombinatorChild {
+		t.Fatalf("complex = %+v", c)
+	}
+	simple := c.Compounds[1].Simple
+	if len(simple) != 4 {
+		t.Fatalf("compound = %+v", simple)
+	}
+	if tsel, ok := simple[0].(*TypeSelector); !ok || tsel.Name != "a" {
+		t.Errorf("simple[0] = %#v", simple[0])
+	}
+	if attr, ok := simple[2].(*AttributeSelector); !ok || attr.Name != "href" || attr.Operator != "^=" || attr.Value != "https" {
+		t.Errorf("simple[2] = %#v", simple[2])
+	}
+	if pc, ok := simple[3].(*PseudoClass); !ok || pc.Name != "not" || len(pc.Selectors) != 1 {
+		t.Errorf("simple[3] = %#v", simple[3])
+	}
+
+	list, _ = ParseSelector(`li:nth-child(-2n+3)`)
+	pc := list[0].Compounds[0].Simple[1].(*PseudoClass)
+	if pc.Nth == nil || pc.Nth.A != -2 || pc.Nth.B != 3 {
+		t.Errorf("nth = %+v", pc.Nth)
+	}
+	list, _ = ParseSelector(`.\31 23`)
+	if cls := list[0].Compounds[0].Simple[0].(*ClassSelector); cls.Name != "123" {
+		t.Errorf("escaped class = %q", cls.Name)
+	}
+}
+
+func TestSelectorSpecificity(t *testing.T) {
+	tests := []struct {
+		sel  string
+		want Specificity
+	}{
+		{"*", Specificity{0, 0, 0}},
+		{"li", Specificity{0, 0, 1}},
+		{"ul li", Specificity{0, 0, 2}},
+		{"ul ol+li", Specificity{0, 0, 3}},
+		{"h1 + *[rel=up]", Specificity{0, 1, 1}},
+		{"ul ol li.red", Specificity{0, 1, 3}},
+		{"li.red.level", Specificity{0, 2, 1}},
+		{"#x34y", Specificity{1, 0, 0}},
+		{"#s12:not(FOO)", Specificity{1, 0, 1}},
+		{".foo :is(.bar, #baz)", Specificity{1, 1, 0}},
+		{":where(#a, .b) p", Specificity{0, 0, 1}},
+		{"li:nth-child(2n of .item)", Specificity{0, 2, 1}},
+		{"p::before", Specificity{0, 0, 2}},
+		{".a, #b", Specificity{1, 0, 0}},
+	}
+	for _, tt := range tests {
+		list, err := ParseSelector(tt.sel)
+		if err != nil {
+			t.Fatalf("ParseSelector(%q): %v", tt.sel, err)
+		}
+		if got := list.Specificity(); got != tt.want {
+			t.Errorf("Specificity(%q) = %v, want %v", tt.sel, got, tt.want)
+		}
+	}
+}
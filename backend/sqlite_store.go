@@ -0,0 +1,301 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// Tür başına SQLite tablosu
+var sqliteTables = map[ConfigKind]string{
+	KindGeneral:  "general_configs",
+	KindSpecific: "specific_configs",
+	KindPages:    "pages_configs",
+	KindPolicy:   "sanitizer_policies",
+}
+
+// *sql.DB ve *sql.Tx ortak arayüzü
+type sqlQuerier interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// SQLiteStore: tüm konfigürasyonları tek bir SQLite veritabanında tutar.
+// İçerik YAML olarak saklanır, böylece API yanıtları dosya store'u ile aynı kalır.
+type SQLiteStore struct {
+	sqliteQueries
+	db *sql.DB
+}
+
+func OpenSQLiteStore(path string) (*SQLiteStore, error) {
+	dsn := fmt.Sprintf("file:%s?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)", path)
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	for _, table := range sqliteTables {
+		_, err := db.Exec(`CREATE TABLE IF NOT EXISTS ` + table + ` (
+			id         TEXT PRIMARY KEY,
+			data       TEXT NOT NULL,
+			updated_at TEXT NOT NULL
+		)`)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS config_revisions (
+		kind          TEXT NOT NULL,
+		id            TEXT NOT NULL,
+		revision      INTEGER NOT NULL,
+		action        TEXT NOT NULL,
+		author        TEXT NOT NULL,
+		created_at    TEXT NOT NULL,
+		restored_from INTEGER NOT NULL DEFAULT 0,
+		data          TEXT NOT NULL,
+		PRIMARY KEY (kind, id, revision)
+	)`)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS config_trash (
+		kind       TEXT NOT NULL,
+		id         TEXT NOT NULL,
+		deleted_by TEXT NOT NULL,
+		deleted_at TEXT NOT NULL,
+		expires_at TEXT NOT NULL,
+		data       TEXT NOT NULL,
+		PRIMARY KEY (kind, id)
+	)`)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &SQLiteStore{sqliteQueries: sqliteQueries{q: db}, db: db}, nil
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteStore) Update(fn func(tx ConfigStore) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(&sqliteQueries{q: tx}); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// ConfigStore sorguları; hem veritabanı hem transaction üzerinde çalışır
+type sqliteQueries struct {
+	q sqlQuerier
+}
+
+func sqliteTable(kind ConfigKind) (string, error) {
+	table, ok := sqliteTables[kind]
+	if !ok {
+		return "", fmt.Errorf("bilinmeyen config türü: %s", kind)
+	}
+	return table, nil
+}
+
+func (s *sqliteQueries) Get(kind ConfigKind, id string) ([]byte, error) {
+	table, err := sqliteTable(kind)
+	if err != nil {
+		return nil, err
+	}
+	var data string
+	err = s.q.QueryRow(`SELECT data FROM `+table+` WHERE id = ?`, id).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, ErrConfigNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []byte(data), nil
+}
+
+func (s *sqliteQueries) List(kind ConfigKind) ([]string, error) {
+	table, err := sqliteTable(kind)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.q.Query(`SELECT id FROM ` + table + ` ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (s *sqliteQueries) Put(kind ConfigKind, id string, data []byte) error {
+	table, err := sqliteTable(kind)
+	if err != nil {
+		return err
+	}
+	_, err = s.q.Exec(`INSERT INTO `+table+` (id, data, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET data = excluded.data, updated_at = excluded.updated_at`,
+		id, string(data), time.Now().UTC().Format(time.RFC3339Nano))
+	return err
+}
+
+func (s *sqliteQueries) Delete(kind ConfigKind, id string) error {
+	table, err := sqliteTable(kind)
+	if err != nil {
+		return err
+	}
+	res, err := s.q.Exec(`DELETE FROM `+table+` WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrConfigNotFound
+	}
+	return nil
+}
+
+func (s *sqliteQueries) AddRevision(kind ConfigKind, id string, rev Revision) (Revision, error) {
+	// Numara aynı INSERT içinde hesaplanır; SQLite yazıcıları sıraya koyduğu için çakışmaz
+	err := s.q.QueryRow(`INSERT INTO config_revisions (kind, id, revision, action, author, created_at, restored_from, data)
+		SELECT ?, ?, COALESCE(MAX(revision), 0) + 1, ?, ?, ?, ?, ?
+		FROM config_revisions WHERE kind = ? AND id = ?
+		RETURNING revision`,
+		string(kind), id, rev.Action, rev.Author, rev.Timestamp.UTC().Format(time.RFC3339Nano), rev.RestoredFrom, string(rev.Data),
+		string(kind), id).Scan(&rev.Number)
+	return rev, err
+}
+
+func (s *sqliteQueries) Revisions(kind ConfigKind, id string) ([]Revision, error) {
+	rows, err := s.q.Query(`SELECT revision, action, author, created_at, restored_from
+		FROM config_revisions WHERE kind = ? AND id = ? ORDER BY revision`, string(kind), id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var revs []Revision
+	for rows.Next() {
+		var rev Revision
+		var ts string
+		if err := rows.Scan(&rev.Number, &rev.Action, &rev.Author, &ts, &rev.RestoredFrom); err != nil {
+			return nil, err
+		}
+		rev.Timestamp, _ = time.Parse(time.RFC3339Nano, ts)
+		revs = append(revs, rev)
+	}
+	return revs, rows.Err()
+}
+
+func (s *sqliteQueries) GetRevision(kind ConfigKind, id string, number int) (*Revision, error) {
+	var rev Revision
+	var ts, data string
+	err := s.q.QueryRow(`SELECT revision, action, author, created_at, restored_from, data
+		FROM config_revisions WHERE kind = ? AND id = ? AND revision = ?`, string(kind), id, number).
+		Scan(&rev.Number, &rev.Action, &rev.Author, &ts, &rev.RestoredFrom, &data)
+	if err == sql.ErrNoRows {
+		return nil, ErrRevisionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	rev.Timestamp, _ = time.Parse(time.RFC3339Nano, ts)
+	rev.Data = []byte(data)
+	return &rev, nil
+}
+
+func (s *sqliteQueries) PutTrash(entry TrashEntry) error {
+	_, err := s.q.Exec(`INSERT INTO config_trash (kind, id, deleted_by, deleted_at, expires_at, data) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(kind, id) DO UPDATE SET deleted_by = excluded.deleted_by, deleted_at = excluded.deleted_at,
+			expires_at = excluded.expires_at, data = excluded.data`,
+		string(entry.Kind), entry.ID, entry.DeletedBy,
+		entry.DeletedAt.UTC().Format(time.RFC3339Nano), entry.ExpiresAt.UTC().Format(time.RFC3339Nano), string(entry.Data))
+	return err
+}
+
+func (s *sqliteQueries) GetTrash(kind ConfigKind, id string) (*TrashEntry, error) {
+	entry := TrashEntry{Kind: kind, ID: id}
+	var deletedAt, expiresAt, data string
+	err := s.q.QueryRow(`SELECT deleted_by, deleted_at, expires_at, data FROM config_trash WHERE kind = ? AND id = ?`,
+		string(kind), id).Scan(&entry.DeletedBy, &deletedAt, &expiresAt, &data)
+	if err == sql.ErrNoRows {
+		return nil, ErrTrashNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	entry.DeletedAt, _ = time.Parse(time.RFC3339Nano, deletedAt)
+	entry.ExpiresAt, _ = time.Parse(time.RFC3339Nano, expiresAt)
+	entry.Data = []byte(data)
+	return &entry, nil
+}
+
+func (s *sqliteQueries) ListTrash() ([]TrashEntry, error) {
+	rows, err := s.q.Query(`SELECT kind, id, deleted_by, deleted_at, expires_at FROM config_trash ORDER BY kind, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var entries []TrashEntry
+	for rows.Next() {
+		var entry TrashEntry
+		var kind, deletedAt, expiresAt string
+		if err := rows.Scan(&kind, &entry.ID, &entry.DeletedBy, &deletedAt, &expiresAt); err != nil {
+			return nil, err
+		}
+		entry.Kind = ConfigKind(kind)
+		entry.DeletedAt, _ = time.Parse(time.RFC3339Nano, deletedAt)
+		entry.ExpiresAt, _ = time.Parse(time.RFC3339Nano, expiresAt)
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+func (s *sqliteQueries) DeleteTrash(kind ConfigKind, id string) error {
+	res, err := s.q.Exec(`DELETE FROM config_trash WHERE kind = ? AND id = ?`, string(kind), id)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrTrashNotFound
+	}
+	return nil
+}
+
+// Fingerprint: tablo başına kayıt sayısı, son güncelleme zamanı ve toplam boyut
+func (s *SQLiteStore) Fingerprint() (string, error) {
+	var fp string
+	for _, kind := range configKinds {
+		var count, size int64
+		var updated string
+		err := s.db.QueryRow(`SELECT COUNT(*), COALESCE(MAX(updated_at), ''), COALESCE(SUM(LENGTH(data)), 0) FROM `+sqliteTables[kind]).
+			Scan(&count, &updated, &size)
+		if err != nil {
+			return "", err
+		}
+		fp += fmt.Sprintf("%s:%d:%s:%d;", kind, count, updated, size)
+	}
+	return fp, nil
+}
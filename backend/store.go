@@ -0,0 +1,612 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigKind, store içinde tutulan konfigürasyon türünü belirtir
+type ConfigKind string
+
+const (
+	KindGeneral  ConfigKind = "general"
+	KindSpecific ConfigKind = "specific"
+	KindPages    ConfigKind = "pages"
+	// Sanitizer politikaları da configlerle aynı store'da (geçmiş ve çöp kutusuyla) tutulur
+	KindPolicy ConfigKind = "policy"
+)
+
+// Tüm konfigürasyon türleri (listeleme sırası)
+var configKinds = []ConfigKind{KindGeneral, KindSpecific, KindPages, KindPolicy}
+
+// ErrConfigNotFound, istenen konfigürasyon store'da yoksa döner
+var ErrConfigNotFound = errors.New("config bulunamadı")
+
+// ErrRevisionNotFound, istenen revizyon yoksa döner
+var ErrRevisionNotFound = errors.New("revizyon bulunamadı")
+
+// ErrTrashNotFound, çöp kutusunda istenen kayıt yoksa döner
+var ErrTrashNotFound = errors.New("çöp kutusunda bulunamadı")
+
+// TrashEntry, silinmiş ve geri yüklenebilir durumdaki bir konfigürasyon
+type TrashEntry struct {
+	Kind      ConfigKind `yaml:"kind" json:"kind"`
+	ID        string     `yaml:"id" json:"id"`
+	DeletedBy string     `yaml:"deleted_by" json:"deleted_by"`
+	DeletedAt time.Time  `yaml:"deleted_at" json:"deleted_at"`
+	// Bu tarihten sonra kalıcı olarak silinir
+	ExpiresAt time.Time `yaml:"expires_at" json:"expires_at"`
+	Data      []byte    `yaml:"-" json:"-"`
+}
+
+// Revision, bir konfigürasyonun belirli bir andaki kaydedilmiş hali
+type Revision struct {
+	Number    int       `yaml:"revision" json:"revision"`
+	Action    string    `yaml:"action" json:"action"` // create, update, delete, rollback
+	Author    string    `yaml:"author" json:"author"`
+	Timestamp time.Time `yaml:"timestamp" json:"timestamp"`
+	// Rollback revizyonlarında geri dönülen revizyon numarası
+	RestoredFrom int `yaml:"restored_from,omitempty" json:"restored_from,omitempty"`
+	// Konfigürasyonun ham YAML içeriği (delete revizyonlarında boş)
+	Data []byte `yaml:"-" json:"-"`
+}
+
+// ConfigStore, konfigürasyonların nerede saklandığını HTTP katmanından gizler.
+// Veri YAML olarak taşınır; parse etmek çağıranın işidir.
+type ConfigStore interface {
+	// Get, verilen türdeki konfigürasyonun ham YAML içeriğini döndürür
+	Get(kind ConfigKind, id string) ([]byte, error)
+	// List, verilen türdeki tüm id'leri sıralı olarak döndürür
+	List(kind ConfigKind) ([]string, error)
+	// Put, konfigürasyonu oluşturur veya üzerine yazar
+	Put(kind ConfigKind, id string, data []byte) error
+	// Delete, konfigürasyonu siler; yoksa ErrConfigNotFound döner
+	Delete(kind ConfigKind, id string) error
+
+	// AddRevision, yeni bir revizyon kaydeder; numarayı store atar
+	AddRevision(kind ConfigKind, id string, rev Revision) (Revision, error)
+	// Revisions, revizyonları eskiden yeniye döndürür (Data hariç)
+	Revisions(kind ConfigKind, id string) ([]Revision, error)
+	// GetRevision, tek bir revizyonu içeriğiyle birlikte döndürür
+	GetRevision(kind ConfigKind, id string, number int) (*Revision, error)
+
+	// PutTrash, silinen konfigürasyonu çöp kutusuna koyar (aynı kind/id varsa üzerine yazar)
+	PutTrash(entry TrashEntry) error
+	// GetTrash, çöp kutusundaki kaydı içeriğiyle döndürür
+	GetTrash(kind ConfigKind, id string) (*TrashEntry, error)
+	// ListTrash, çöp kutusundaki tüm kayıtları döndürür (Data hariç)
+	ListTrash() ([]TrashEntry, error)
+	// DeleteTrash, kaydı çöp kutusundan kalıcı olarak siler
+	DeleteTrash(kind ConfigKind, id string) error
+}
+
+// TxStore, birden fazla yazmayı tek transaction içinde uygulayabilen store'lar için.
+// fn hata dönerse hiçbir değişiklik kalıcı olmaz.
+type TxStore interface {
+	ConfigStore
+	Update(fn func(tx ConfigStore) error) error
+}
+
+// Store transaction destekliyorsa fn'i transaction içinde, desteklemiyorsa doğrudan çalıştır
+func withTx(s ConfigStore, fn func(tx ConfigStore) error) error {
+	if ts, ok := s.(TxStore); ok {
+		return ts.Update(fn)
+	}
+	return fn(s)
+}
+
+// FileStore: her konfigürasyon dir altında ayrı bir YAML dosyası
+//
+//	general  -> {id}.yaml
+//	specific -> specific_{id}.yaml
+//	pages    -> pages_{id}.yaml
+type FileStore struct {
+	dir string
+	// Aynı dosyaya eşzamanlı yazanları sıraya koymak için path başına kilit
+	locks sync.Map
+}
+
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+// Türe göre dosya adı öneki
+func kindPrefix(kind ConfigKind) string {
+	switch kind {
+	case KindSpecific:
+		return "specific_"
+	case KindPages:
+		return "pages_"
+	case KindPolicy:
+		return "policy_"
+	}
+	return ""
+}
+
+// Dosya adından tür ve id çıkar
+func parseConfigFileName(name string) (ConfigKind, string, bool) {
+	if filepath.Ext(name) != ".yaml" {
+		return "", "", false
+	}
+	base := strings.TrimSuffix(name, ".yaml")
+	switch {
+	case strings.HasPrefix(base, "specific_") && len(base) > len("specific_"):
+		return KindSpecific, base[len("specific_"):], true
+	case strings.HasPrefix(base, "pages_") && len(base) > len("pages_"):
+		return KindPages, base[len("pages_"):], true
+	case strings.HasPrefix(base, "policy_") && len(base) > len("policy_"):
+		return KindPolicy, base[len("policy_"):], true
+	case base != "":
+		return KindGeneral, base, true
+	}
+	return "", "", false
+}
+
+// Dosya yolları kullanıcıdan gelen id'den türetilir; yazan/silen metotlar
+// id'yi ayrıca validateConfigID ile doğrular (bkz. ids.go)
+func (s *FileStore) path(kind ConfigKind, id string) string {
+	return filepath.Join(s.dir, kindPrefix(kind)+id+".yaml")
+}
+
+func (s *FileStore) Get(kind ConfigKind, id string) ([]byte, error) {
+	if err := validateConfigID(kind, id); err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadFile(s.path(kind, id))
+	if os.IsNotExist(err) {
+		return nil, ErrConfigNotFound
+	}
+	return b, err
+}
+
+func (s *FileStore) List(kind ConfigKind) ([]string, error) {
+	files, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		if k, id, ok := parseConfigFileName(file.Name()); ok && k == kind && validateConfigID(k, id) == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (s *FileStore) lock(path string) func() {
+	m, _ := s.locks.LoadOrStore(path, &sync.Mutex{})
+	mu := m.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
+// Put, içeriği önce geçici dosyaya yazıp fsync eder, sonra rename ile yerine koyar.
+// Böylece okuyucular ya eski ya yeni dosyanın tamamını görür, yarım yazılmış YAML asla görünmez.
+func (s *FileStore) Put(kind ConfigKind, id string, data []byte) error {
+	if err := validateConfigID(kind, id); err != nil {
+		return err
+	}
+	path := s.path(kind, id)
+	defer s.lock(path)()
+	return writeFileAtomic(path, data, 0644)
+}
+
+func (s *FileStore) Delete(kind ConfigKind, id string) error {
+	if err := validateConfigID(kind, id); err != nil {
+		return err
+	}
+	path := s.path(kind, id)
+	defer s.lock(path)()
+	err := os.Remove(path)
+	if os.IsNotExist(err) {
+		return ErrConfigNotFound
+	}
+	return err
+}
+
+// Revizyonlar: dir/.history/{kind}/{id}/{numara}.yaml
+func (s *FileStore) historyDir(kind ConfigKind, id string) string {
+	return filepath.Join(s.dir, ".history", string(kind), id)
+}
+
+// Revizyon dosyası: meta alanlar + ham içerik
+type fileRevision struct {
+	Revision `yaml:",inline"`
+	Content  string `yaml:"content,omitempty"`
+}
+
+func (s *FileStore) readRevision(path string, withData bool) (*Revision, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var fr fileRevision
+	if err := yaml.Unmarshal(b, &fr); err != nil {
+		return nil, err
+	}
+	rev := fr.Revision
+	if withData {
+		rev.Data = []byte(fr.Content)
+	}
+	return &rev, nil
+}
+
+func (s *FileStore) AddRevision(kind ConfigKind, id string, rev Revision) (Revision, error) {
+	if err := validateConfigID(kind, id); err != nil {
+		return rev, err
+	}
+	dir := s.historyDir(kind, id)
+	defer s.lock(dir)()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return rev, err
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return rev, err
+	}
+	rev.Number = 1
+	for _, file := range files {
+		if n, err := strconv.Atoi(strings.TrimSuffix(file.Name(), ".yaml")); err == nil && n >= rev.Number {
+			rev.Number = n + 1
+		}
+	}
+	b, err := yaml.Marshal(fileRevision{Revision: rev, Content: string(rev.Data)})
+	if err != nil {
+		return rev, err
+	}
+	return rev, writeFileAtomic(filepath.Join(dir, strconv.Itoa(rev.Number)+".yaml"), b, 0644)
+}
+
+func (s *FileStore) Revisions(kind ConfigKind, id string) ([]Revision, error) {
+	dir := s.historyDir(kind, id)
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var revs []Revision
+	for _, file := range files {
+		if filepath.Ext(file.Name()) != ".yaml" {
+			continue
+		}
+		rev, err := s.readRevision(filepath.Join(dir, file.Name()), false)
+		if err != nil {
+			continue
+		}
+		revs = append(revs, *rev)
+	}
+	sort.Slice(revs, func(i, j int) bool { return revs[i].Number < revs[j].Number })
+	return revs, nil
+}
+
+func (s *FileStore) GetRevision(kind ConfigKind, id string, number int) (*Revision, error) {
+	rev, err := s.readRevision(filepath.Join(s.historyDir(kind, id), strconv.Itoa(number)+".yaml"), true)
+	if os.IsNotExist(err) {
+		return nil, ErrRevisionNotFound
+	}
+	return rev, err
+}
+
+// Çöp kutusu: dir/.trash/{kind}/{id}.yaml
+func (s *FileStore) trashPath(kind ConfigKind, id string) string {
+	return filepath.Join(s.dir, ".trash", string(kind), id+".yaml")
+}
+
+// Çöp kutusu dosyası: meta alanlar + ham içerik
+type fileTrashEntry struct {
+	TrashEntry `yaml:",inline"`
+	Content    string `yaml:"content"`
+}
+
+func (s *FileStore) readTrash(path string, withData bool) (*TrashEntry, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var ft fileTrashEntry
+	if err := yaml.Unmarshal(b, &ft); err != nil {
+		return nil, err
+	}
+	entry := ft.TrashEntry
+	if withData {
+		entry.Data = []byte(ft.Content)
+	}
+	return &entry, nil
+}
+
+func (s *FileStore) PutTrash(entry TrashEntry) error {
+	if err := validateConfigID(entry.Kind, entry.ID); err != nil {
+		return err
+	}
+	path := s.trashPath(entry.Kind, entry.ID)
+	defer s.lock(path)()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	b, err := yaml.Marshal(fileTrashEntry{TrashEntry: entry, Content: string(entry.Data)})
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, b, 0644)
+}
+
+func (s *FileStore) GetTrash(kind ConfigKind, id string) (*TrashEntry, error) {
+	entry, err := s.readTrash(s.trashPath(kind, id), true)
+	if os.IsNotExist(err) {
+		return nil, ErrTrashNotFound
+	}
+	return entry, err
+}
+
+func (s *FileStore) ListTrash() ([]TrashEntry, error) {
+	var entries []TrashEntry
+	for _, kind := range configKinds {
+		dir := filepath.Join(s.dir, ".trash", string(kind))
+		files, err := ioutil.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if filepath.Ext(file.Name()) != ".yaml" {
+				continue
+			}
+			if entry, err := s.readTrash(filepath.Join(dir, file.Name()), false); err == nil {
+				entries = append(entries, *entry)
+			}
+		}
+	}
+	return entries, nil
+}
+
+func (s *FileStore) DeleteTrash(kind ConfigKind, id string) error {
+	path := s.trashPath(kind, id)
+	defer s.lock(path)()
+	err := os.Remove(path)
+	if os.IsNotExist(err) {
+		return ErrTrashNotFound
+	}
+	return err
+}
+
+// Geçici dosya + fsync + rename ile atomik dosya yazımı
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	// ".yaml" ile bitmediği için listelemede ve parmak izinde görünmez
+	tmp, err := ioutil.TempFile(dir, "."+filepath.Base(path)+".tmp-")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName) // rename başarılıysa zaten yok
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		return err
+	}
+	// Rename'in kendisinin de kalıcı olması için klasörü fsync et
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+// Fingerprint: klasördeki YAML dosyalarının ad, boyut ve değişiklik zamanından üretilir
+func (s *FileStore) Fingerprint() (string, error) {
+	files, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return "", err
+	}
+	h := fnv.New64a()
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".yaml" {
+			continue
+		}
+		fmt.Fprintf(h, "%s|%d|%d\n", file.Name(), file.Size(), file.ModTime().UnixNano())
+	}
+	return strconv.FormatUint(h.Sum64(), 16), nil
+}
+
+// MemoryStore: her şeyi bellekte tutar, testler ve geçici kurulumlar için
+type MemoryStore struct {
+	mu        sync.RWMutex
+	data      map[ConfigKind]map[string][]byte
+	revisions map[string][]Revision
+	trash     map[string]TrashEntry
+	version   uint64
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		data:      make(map[ConfigKind]map[string][]byte),
+		revisions: make(map[string][]Revision),
+		trash:     make(map[string]TrashEntry),
+	}
+}
+
+func (s *MemoryStore) Get(kind ConfigKind, id string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	b, ok := s.data[kind][id]
+	if !ok {
+		return nil, ErrConfigNotFound
+	}
+	return append([]byte(nil), b...), nil
+}
+
+func (s *MemoryStore) List(kind ConfigKind) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids := make([]string, 0, len(s.data[kind]))
+	for id := range s.data[kind] {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func (s *MemoryStore) Put(kind ConfigKind, id string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data[kind] == nil {
+		s.data[kind] = make(map[string][]byte)
+	}
+	s.data[kind][id] = append([]byte(nil), data...)
+	s.version++
+	return nil
+}
+
+func (s *MemoryStore) Delete(kind ConfigKind, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.data[kind][id]; !ok {
+		return ErrConfigNotFound
+	}
+	delete(s.data[kind], id)
+	s.version++
+	return nil
+}
+
+func (s *MemoryStore) AddRevision(kind ConfigKind, id string, rev Revision) (Revision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := string(kind) + "/" + id
+	rev.Number = len(s.revisions[key]) + 1
+	rev.Data = append([]byte(nil), rev.Data...)
+	s.revisions[key] = append(s.revisions[key], rev)
+	return rev, nil
+}
+
+func (s *MemoryStore) Revisions(kind ConfigKind, id string) ([]Revision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var revs []Revision
+	for _, rev := range s.revisions[string(kind)+"/"+id] {
+		rev.Data = nil
+		revs = append(revs, rev)
+	}
+	return revs, nil
+}
+
+func (s *MemoryStore) GetRevision(kind ConfigKind, id string, number int) (*Revision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	revs := s.revisions[string(kind)+"/"+id]
+	if number < 1 || number > len(revs) {
+		return nil, ErrRevisionNotFound
+	}
+	rev := revs[number-1]
+	rev.Data = append([]byte(nil), rev.Data...)
+	return &rev, nil
+}
+
+func (s *MemoryStore) PutTrash(entry TrashEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry.Data = append([]byte(nil), entry.Data...)
+	s.trash[string(entry.Kind)+"/"+entry.ID] = entry
+	return nil
+}
+
+func (s *MemoryStore) GetTrash(kind ConfigKind, id string) (*TrashEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entry, ok := s.trash[string(kind)+"/"+id]
+	if !ok {
+		return nil, ErrTrashNotFound
+	}
+	entry.Data = append([]byte(nil), entry.Data...)
+	return &entry, nil
+}
+
+func (s *MemoryStore) ListTrash() ([]TrashEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var entries []TrashEntry
+	for _, entry := range s.trash {
+		entry.Data = nil
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Kind != entries[j].Kind {
+			return entries[i].Kind < entries[j].Kind
+		}
+		return entries[i].ID < entries[j].ID
+	})
+	return entries, nil
+}
+
+func (s *MemoryStore) DeleteTrash(kind ConfigKind, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := string(kind) + "/" + id
+	if _, ok := s.trash[key]; !ok {
+		return ErrTrashNotFound
+	}
+	delete(s.trash, key)
+	return nil
+}
+
+func (s *MemoryStore) Fingerprint() (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return strconv.FormatUint(s.version, 10), nil
+}
+
+// src içindeki tüm konfigürasyonları dst'ye kopyala (örn. configs klasöründen SQLite'a geçiş)
+func importConfigs(dst, src ConfigStore) (int, error) {
+	count := 0
+	err := withTx(dst, func(tx ConfigStore) error {
+		for _, kind := range configKinds {
+			ids, err := src.List(kind)
+			if err != nil {
+				return err
+			}
+			for _, id := range ids {
+				b, err := src.Get(kind, id)
+				if err != nil {
+					return err
+				}
+				if err := tx.Put(kind, id, b); err != nil {
+					return err
+				}
+				count++
+			}
+		}
+		return nil
+	})
+	return count, err
+}
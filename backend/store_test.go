@@ -0,0 +1,211 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Her ConfigStore implementasyonunun sağlaması gereken davranış
+func testConfigStoreContract(t *testing.T, s ConfigStore) {
+	if _, err := s.Get(KindGeneral, "missing"); err != ErrConfigNotFound {
+		t.Fatalf("Get missing: want ErrConfigNotFound, got %v", err)
+	}
+	if err := s.Delete(KindGeneral, "missing"); err != ErrConfigNotFound {
+		t.Fatalf("Delete missing: want ErrConfigNotFound, got %v", err)
+	}
+
+	if err := s.Put(KindGeneral, "demo", []byte("id: demo\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Put(KindSpecific, "demo", []byte("id: specific-demo\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Put(KindPages, "blog", []byte("id: blog\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Aynı id farklı türlerde birbirini ezmemeli
+	b, err := s.Get(KindGeneral, "demo")
+	if err != nil || string(b) != "id: demo\n" {
+		t.Fatalf("Get general: %q %v", b, err)
+	}
+	b, err = s.Get(KindSpecific, "demo")
+	if err != nil || string(b) != "id: specific-demo\n" {
+		t.Fatalf("Get specific: %q %v", b, err)
+	}
+
+	for kind, want := range map[ConfigKind]string{KindGeneral: "demo", KindSpecific: "demo", KindPages: "blog"} {
+		ids, err := s.List(kind)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ids) != 1 || ids[0] != want {
+			t.Errorf("List(%s) = %v, want [%s]", kind, ids, want)
+		}
+	}
+
+	if err := s.Put(KindGeneral, "demo", []byte("id: demo\nname: v2\n")); err != nil {
+		t.Fatal(err)
+	}
+	b, _ = s.Get(KindGeneral, "demo")
+	if string(b) != "id: demo\nname: v2\n" {
+		t.Errorf("Put should overwrite, got %q", b)
+	}
+
+	if err := s.Delete(KindGeneral, "demo"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get(KindGeneral, "demo"); err != ErrConfigNotFound {
+		t.Errorf("Get after delete: want ErrConfigNotFound, got %v", err)
+	}
+	if _, err := s.Get(KindSpecific, "demo"); err != nil {
+		t.Errorf("deleting general must not touch specific: %v", err)
+	}
+
+	// Revizyonlar
+	if revs, err := s.Revisions(KindPages, "blog"); err != nil || len(revs) != 0 {
+		t.Fatalf("Revisions without history = %v, %v", revs, err)
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	for i, data := range []string{"id: blog\n", "id: blog\nname: v2\n"} {
+		rev, err := s.AddRevision(KindPages, "blog", Revision{Action: "update", Author: "alice", Timestamp: now, Data: []byte(data)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rev.Number != i+1 {
+			t.Errorf("AddRevision number = %d, want %d", rev.Number, i+1)
+		}
+	}
+	s.AddRevision(KindGeneral, "blog", Revision{Action: "create", Author: "bob", Timestamp: now, Data: []byte("x: 1\n")})
+	revs, err := s.Revisions(KindPages, "blog")
+	if err != nil || len(revs) != 2 {
+		t.Fatalf("Revisions = %v, %v", revs, err)
+	}
+	if revs[1].Number != 2 || revs[1].Author != "alice" || !revs[1].Timestamp.Equal(now) || revs[1].Data != nil {
+		t.Errorf("Revisions[1] = %+v", revs[1])
+	}
+	rev, err := s.GetRevision(KindPages, "blog", 2)
+	if err != nil || string(rev.Data) != "id: blog\nname: v2\n" {
+		t.Errorf("GetRevision = %+v, %v", rev, err)
+	}
+	if _, err := s.GetRevision(KindPages, "blog", 3); err != ErrRevisionNotFound {
+		t.Errorf("GetRevision missing: want ErrRevisionNotFound, got %v", err)
+	}
+
+	// Çöp kutusu
+	if _, err := s.GetTrash(KindGeneral, "demo"); err != ErrTrashNotFound {
+		t.Errorf("GetTrash missing: want ErrTrashNotFound, got %v", err)
+	}
+	entry := TrashEntry{Kind: KindGeneral, ID: "demo", DeletedBy: "alice", DeletedAt: now, ExpiresAt: now.Add(time.Hour), Data: []byte("id: demo\n")}
+	if err := s.PutTrash(entry); err != nil {
+		t.Fatal(err)
+	}
+	s.PutTrash(TrashEntry{Kind: KindPages, ID: "blog", DeletedBy: "bob", DeletedAt: now, ExpiresAt: now, Data: []byte("id: blog\n")})
+	got, err := s.GetTrash(KindGeneral, "demo")
+	if err != nil || string(got.Data) != "id: demo\n" || got.DeletedBy != "alice" || !got.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("GetTrash = %+v, %v", got, err)
+	}
+	trash, err := s.ListTrash()
+	if err != nil || len(trash) != 2 || trash[0].Data != nil {
+		t.Errorf("ListTrash = %+v, %v", trash, err)
+	}
+	if err := s.DeleteTrash(KindGeneral, "demo"); err != nil {
+		t.Errorf("DeleteTrash: %v", err)
+	}
+	if err := s.DeleteTrash(KindGeneral, "demo"); err != ErrTrashNotFound {
+		t.Errorf("DeleteTrash twice: want ErrTrashNotFound, got %v", err)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	testConfigStoreContract(t, NewFileStore(t.TempDir()))
+}
+
+func TestMemoryStore(t *testing.T) {
+	testConfigStoreContract(t, NewMemoryStore())
+}
+
+func TestSQLiteStore(t *testing.T) {
+	s, err := OpenSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	testConfigStoreContract(t, s)
+}
+
+func TestSQLiteStoreUpdateRollback(t *testing.T) {
+	s, err := OpenSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.Put(KindGeneral, "keep", []byte("id: keep\n"))
+
+	boom := errors.New("boom")
+	err = s.Update(func(tx ConfigStore) error {
+		if err := tx.Put(KindGeneral, "new", []byte("id: new\n")); err != nil {
+			return err
+		}
+		if err := tx.Delete(KindGeneral, "keep"); err != nil {
+			return err
+		}
+		return boom
+	})
+	if err != boom {
+		t.Fatalf("Update: want boom, got %v", err)
+	}
+	if _, err := s.Get(KindGeneral, "new"); err != ErrConfigNotFound {
+		t.Errorf("rolled back Put must not be visible: %v", err)
+	}
+	if _, err := s.Get(KindGeneral, "keep"); err != nil {
+		t.Errorf("rolled back Delete must not be applied: %v", err)
+	}
+}
+
+func TestImportConfigs(t *testing.T) {
+	src := NewFileStore(t.TempDir())
+	src.Put(KindGeneral, "demo", []byte("id: demo\n"))
+	src.Put(KindPages, "blog", []byte("id: blog\n"))
+	dst := NewMemoryStore()
+	n, err := importConfigs(dst, src)
+	if err != nil || n != 2 {
+		t.Fatalf("importConfigs = %d, %v", n, err)
+	}
+	if b, err := dst.Get(KindPages, "blog"); err != nil || string(b) != "id: blog\n" {
+		t.Errorf("imported pages config = %q, %v", b, err)
+	}
+}
+
+// Testin süresince global store'u boş bir MemoryStore ile değiştirir
+func useMemoryStore(t *testing.T) *MemoryStore {
+	t.Helper()
+	old := store
+	s := NewMemoryStore()
+	store = s
+	t.Cleanup(func() { store = old })
+	return s
+}
+
+// Yük testleri için geçici klasörde FileStore; configs/ altındaki verilen örnekler kopyalanır,
+// testlerin yazdıkları depodaki dosyalara dokunmaz
+func useTempConfigDir(tb testing.TB, fixtures ...string) string {
+	tb.Helper()
+	dir := tb.TempDir()
+	for _, name := range fixtures {
+		b, err := ioutil.ReadFile(filepath.Join(configDir, name))
+		if err != nil {
+			tb.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, name), b, 0644); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	old := store
+	store = NewFileStore(dir)
+	tb.Cleanup(func() { store = old })
+	return dir
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Silinen konfigürasyonların çöp kutusunda kalma süresi (-trash-retention)
+var trashRetention = 30 * 24 * time.Hour
+
+// Geri yüklenecek id ile aktif bir config zaten varsa
+var errRestoreConflict = errors.New("aynı id ile config zaten var")
+
+// Silinmek istenen config'i extends eden configler varsa; çocuklar sessizce bozulmasın diye silme reddedilir
+type extendedByError struct {
+	children []string
+}
+
+func (e *extendedByError) Error() string {
+	return "config extends ediliyor: " + strings.Join(e.children, ", ")
+}
+
+// Konfigürasyonu çöp kutusuna taşı ve silme revizyonunu kaydet
+func deleteConfig(kind ConfigKind, id string, author string) error {
+	return withTx(store, func(tx ConfigStore) error {
+		data, err := tx.Get(kind, id)
+		if err != nil {
+			return err
+		}
+		children, err := extendingConfigs(tx, kind, id)
+		if err != nil {
+			return err
+		}
+		if len(children) > 0 {
+			return &extendedByError{children: children}
+		}
+		now := time.Now().UTC()
+		err = tx.PutTrash(TrashEntry{
+			Kind:      kind,
+			ID:        id,
+			DeletedBy: author,
+			DeletedAt: now,
+			ExpiresAt: now.Add(trashRetention),
+			Data:      data,
+		})
+		if err != nil {
+			return err
+		}
+		if err := tx.Delete(kind, id); err != nil {
+			return err
+		}
+		_, err = tx.AddRevision(kind, id, Revision{Action: "delete", Author: author, Timestamp: now})
+		return err
+	})
+}
+
+// deleteConfig hatasını yaz: extends edilen config 409, diğerleri 404 (msg)
+func writeDeleteError(w http.ResponseWriter, err error, msg string) {
+	var eb *extendedByError
+	if errors.As(err, &eb) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":       "Config başka configler tarafından extends ediliyor; önce onları güncelleyin",
+			"extended_by": eb.children,
+		})
+		return
+	}
+	w.WriteHeader(http.StatusNotFound)
+	w.Write([]byte(`{"error": "` + msg + `"}`))
+}
+
+// Süresi dolmuş çöp kutusu kayıtlarını kalıcı olarak sil
+func purgeExpiredTrash(now time.Time) (int, error) {
+	entries, err := store.ListTrash()
+	if err != nil {
+		return 0, err
+	}
+	purged := 0
+	for _, entry := range entries {
+		if now.Before(entry.ExpiresAt) {
+			continue
+		}
+		if err := store.DeleteTrash(entry.Kind, entry.ID); err != nil && err != ErrTrashNotFound {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// Süresi dolan kayıtları periyodik olarak temizle
+func startTrashPurger(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if n, err := purgeExpiredTrash(time.Now()); err != nil {
+				log.Printf("Çöp kutusu temizlenemedi: %v", err)
+			} else if n > 0 {
+				log.Printf("Çöp kutusundan %d kayıt kalıcı olarak silindi", n)
+			}
+		}
+	}()
+}
+
+// URL'deki {kind} (general, specific, pages) ve {id} parametreleri. Geçersizse 400 yazar.
+func trashTarget(w http.ResponseWriter, r *http.Request) (ConfigKind, string, bool) {
+	vars := mux.Vars(r)
+	kind := ConfigKind(vars["kind"])
+	known := false
+	for _, k := range configKinds {
+		known = known || k == kind
+	}
+	if !known {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": "Geçersiz config türü"}`))
+		return "", "", false
+	}
+	if err := validateConfigID(kind, vars["id"]); err != nil {
+		writeInvalidID(w, err)
+		return "", "", false
+	}
+	return kind, vars["id"], true
+}
+
+// GET /api/trash?kind=pages
+func handleListTrash(w http.ResponseWriter, r *http.Request) {
+	entries, err := store.ListTrash()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error": "Çöp kutusu okunamadı"}`))
+		return
+	}
+	kind := ConfigKind(r.URL.Query().Get("kind"))
+	now := time.Now()
+	result := []TrashEntry{}
+	for _, entry := range entries {
+		// Süresi dolmuş ama henüz temizlenmemiş kayıtlar gösterilmez
+		if !now.Before(entry.ExpiresAt) {
+			continue
+		}
+		if kind != "" && entry.Kind != kind {
+			continue
+		}
+		result = append(result, entry)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// POST /api/trash/{kind}/{id}/restore
+// Aynı id ile yeni bir config oluşturulmuşsa 409 döner
+func handleRestoreTrash(w http.ResponseWriter, r *http.Request) {
+	kind, id, ok := trashTarget(w, r)
+	if !ok {
+		return
+	}
+	defer lockConfig(kind, id)()
+
+	var added Revision
+	var data []byte
+	err := withTx(store, func(tx ConfigStore) error {
+		entry, err := tx.GetTrash(kind, id)
+		if err != nil {
+			return err
+		}
+		if !time.Now().Before(entry.ExpiresAt) {
+			return ErrTrashNotFound
+		}
+		if _, err := tx.Get(kind, id); err == nil {
+			return errRestoreConflict
+		}
+		data = entry.Data
+		if err := tx.Put(kind, id, data); err != nil {
+			return err
+		}
+		if err := tx.DeleteTrash(kind, id); err != nil {
+			return err
+		}
+		added, err = tx.AddRevision(kind, id, Revision{Action: "restore", Author: requestAuthor(r), Timestamp: time.Now().UTC(), Data: data})
+		return err
+	})
+	switch err {
+	case nil:
+	case ErrTrashNotFound:
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error": "Çöp kutusunda bulunamadı"}`))
+		return
+	case errRestoreConflict:
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"error": "Aynı id ile bir config zaten var"}`))
+		return
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error": "Geri yükleme başarısız"}`))
+		return
+	}
+	setStoredETag(w, kind, id)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":  "Config geri yüklendi",
+		"revision": newRevisionInfo(kind, added),
+	})
+}
+
+// DELETE /api/trash/{kind}/{id}
+// Kaydı çöp kutusundan kalıcı olarak siler; revizyon geçmişi korunur
+func handlePurgeTrash(w http.ResponseWriter, r *http.Request) {
+	kind, id, ok := trashTarget(w, r)
+	if !ok {
+		return
+	}
+	defer lockConfig(kind, id)()
+	err := store.DeleteTrash(kind, id)
+	if err == ErrTrashNotFound {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error": "Çöp kutusunda bulunamadı"}`))
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error": "Kalıcı silme başarısız"}`))
+		return
+	}
+	w.Write([]byte(`{"message": "Config kalıcı olarak silindi"}`))
+}
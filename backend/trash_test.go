@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestTrashRestoreAndPurge(t *testing.T) {
+	useMemoryStore(t)
+	router := newRouter()
+
+	body := `{"id": "blog", "name": "Blog", "actions": []}`
+	if w := doRequest(router, "POST", "/api/pages", body, nil); w.Code != http.StatusCreated {
+		t.Fatalf("POST: %d %s", w.Code, w.Body.String())
+	}
+	if w := doRequest(router, "DELETE", "/api/pages/blog", "", map[string]string{"X-User": "alice"}); w.Code != http.StatusOK {
+		t.Fatalf("DELETE: %d %s", w.Code, w.Body.String())
+	}
+	if w := doRequest(router, "GET", "/api/pages/blog", "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("GET after delete: %d", w.Code)
+	}
+
+	w := doRequest(router, "GET", "/api/trash?kind=pages", "", nil)
+	var entries []TrashEntry
+	if err := json.Unmarshal(w.Body.Bytes(), &entries); err != nil || len(entries) != 1 {
+		t.Fatalf("trash list: %d %s", w.Code, w.Body.String())
+	}
+	if entries[0].ID != "blog" || entries[0].DeletedBy != "alice" || !entries[0].ExpiresAt.After(entries[0].DeletedAt) {
+		t.Errorf("trash entry = %+v", entries[0])
+	}
+
+	// Aynı id ile yeniden oluşturulmuşsa geri yükleme çakışır
+	doRequest(router, "POST", "/api/pages", `{"id": "blog", "name": "Yeni"}`, nil)
+	if w := doRequest(router, "POST", "/api/trash/pages/blog/restore", "", nil); w.Code != http.StatusConflict {
+		t.Errorf("restore over existing: %d %s", w.Code, w.Body.String())
+	}
+	doRequest(router, "DELETE", "/api/pages/blog", "", nil)
+
+	// İkinci silme çöp kutusundaki kaydın yerini alır
+	if w := doRequest(router, "POST", "/api/trash/pages/blog/restore", "", nil); w.Code != http.StatusOK || w.Header().Get("ETag") == "" {
+		t.Fatalf("restore: %d %s", w.Code, w.Body.String())
+	}
+	cfg, err := loadPagesConfig("blog")
+	if err != nil || cfg.Name != "Yeni" {
+		t.Errorf("restored config = %+v, %v", cfg, err)
+	}
+	revs, _ := store.Revisions(KindPages, "blog")
+	if last := revs[len(revs)-1]; last.Action != "restore" {
+		t.Errorf("last revision = %+v", last)
+	}
+	if w := doRequest(router, "POST", "/api/trash/pages/blog/restore", "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("restore twice: %d", w.Code)
+	}
+
+	// Kalıcı silme
+	doRequest(router, "DELETE", "/api/pages/blog", "", nil)
+	if w := doRequest(router, "DELETE", "/api/trash/pages/blog", "", nil); w.Code != http.StatusOK {
+		t.Fatalf("purge: %d %s", w.Code, w.Body.String())
+	}
+	if w := doRequest(router, "POST", "/api/trash/pages/blog/restore", "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("restore after purge: %d", w.Code)
+	}
+	if w := doRequest(router, "DELETE", "/api/trash/unknown/blog", "", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("purge with unknown kind: %d", w.Code)
+	}
+}
+
+func TestPurgeExpiredTrash(t *testing.T) {
+	useMemoryStore(t)
+	now := time.Now().UTC()
+	store.PutTrash(TrashEntry{Kind: KindGeneral, ID: "old", DeletedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(-time.Hour)})
+	store.PutTrash(TrashEntry{Kind: KindGeneral, ID: "fresh", DeletedAt: now, ExpiresAt: now.Add(time.Hour)})
+
+	n, err := purgeExpiredTrash(now)
+	if err != nil || n != 1 {
+		t.Fatalf("purgeExpiredTrash = %d, %v", n, err)
+	}
+	if _, err := store.GetTrash(KindGeneral, "old"); err != ErrTrashNotFound {
+		t.Errorf("expired entry still in trash: %v", err)
+	}
+	if _, err := store.GetTrash(KindGeneral, "fresh"); err != nil {
+		t.Errorf("fresh entry purged: %v", err)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"net/http"
+)
+
+// PUT body'sindeki id'yi URL'deki id ile uzlaştırır: boşsa URL'dekini kullanır,
+// metin değilse 422, farklıysa 409 yazar ve false döner
+func reconcileBodyID(w http.ResponseWriter, bodyID interface{}, pathID string) (string, bool) {
+	switch v := bodyID.(type) {
+	case nil:
+		return pathID, true
+	case string:
+		if v == "" || v == pathID {
+			return pathID, true
+		}
+	default:
+		writeFieldError(w, "id", codeInvalidType, "metin olmalı")
+		return "", false
+	}
+	w.WriteHeader(http.StatusConflict)
+	w.Write([]byte(`{"error": "Body'deki id URL'deki id ile uyuşmuyor"}`))
+	return "", false
+}
+
+// PUT hedefini denetler: config yoksa ve ?upsert=true verilmemişse 404 yazar.
+// created, PUT'un yeni bir config oluşturacağını belirtir. Çağıran lockConfig ile id'yi kilitlemiş olmalı.
+func checkPutTarget(w http.ResponseWriter, r *http.Request, kind ConfigKind, id string) (created bool, ok bool) {
+	_, err := store.Get(kind, id)
+	if err == nil {
+		return false, true
+	}
+	if err != ErrConfigNotFound {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error": "Config okunamadı"}`))
+		return false, false
+	}
+	if r.URL.Query().Get("upsert") != "true" {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error": "Config bulunamadı (oluşturmak için ?upsert=true)"}`))
+		return false, false
+	}
+	return true, true
+}
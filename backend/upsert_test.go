@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestPutIDSemantics(t *testing.T) {
+	useMemoryStore(t)
+	router := newRouter()
+
+	for _, tt := range []struct {
+		kind   ConfigKind
+		prefix string
+		body   string // %q yerine body'deki id yazılır
+	}{
+		{KindGeneral, "/api/configuration", `{"id": %q, "actions": []}`},
+		{KindSpecific, "/api/specific", `{"id": %q, "actions": []}`},
+		{KindPages, "/api/pages", `{"id": %q, "name": "x"}`},
+	} {
+		body := func(id string) string { return fmt.Sprintf(tt.body, id) }
+
+		// Olmayan config'e PUT: upsert istenmedikçe 404
+		if w := doRequest(router, "PUT", tt.prefix+"/demo", body("demo"), nil); w.Code != http.StatusNotFound {
+			t.Errorf("%s PUT missing: %d %s", tt.kind, w.Code, w.Body.String())
+		}
+		if w := doRequest(router, "PUT", tt.prefix+"/demo?upsert=true", body("demo"), nil); w.Code != http.StatusCreated {
+			t.Errorf("%s PUT upsert: %d %s", tt.kind, w.Code, w.Body.String())
+		}
+
+		// Uyuşmayan id reddedilir ve hiçbir şey yazılmaz
+		if w := doRequest(router, "PUT", tt.prefix+"/demo", body("other"), nil); w.Code != http.StatusConflict {
+			t.Errorf("%s PUT mismatch: %d %s", tt.kind, w.Code, w.Body.String())
+		}
+		if _, err := store.Get(tt.kind, "other"); err != ErrConfigNotFound {
+			t.Errorf("%s mismatched PUT wrote body id: %v", tt.kind, err)
+		}
+
+		// Metin olmayan id bozuk gövdedir, çakışma değil
+		w := doRequest(router, "PUT", tt.prefix+"/demo", `{"id": 5}`, nil)
+		if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), `"path":"id","code":"invalid_type"`) {
+			t.Errorf("%s PUT non-string id: %d %s", tt.kind, w.Code, w.Body.String())
+		}
+
+		// Eksik id URL'den doldurulur
+		if w := doRequest(router, "PUT", tt.prefix+"/demo", body(""), nil); w.Code != http.StatusOK {
+			t.Errorf("%s PUT without id: %d %s", tt.kind, w.Code, w.Body.String())
+		}
+		cfg, err := loadConfig(tt.kind, "demo")
+		if err != nil || cfg["id"] != "demo" {
+			t.Errorf("%s stored id = %v, %v", tt.kind, cfg["id"], err)
+		}
+	}
+}
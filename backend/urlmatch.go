@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	neturl "net/url"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strings"
+)
+
+// Datasource.URLs anahtarlarının eşleşme biçimleri:
+//
+//	/about, =/about  yalnızca /about (exact); / de yalnızca kök yola uyar
+//	/post/           /post/ ve altındaki her yol (prefix); yalnız / ile biten anahtarlar
+//	/post/*          tek yol parçası, /post/** ise altındaki her yol (glob)
+//	~^/p/[0-9]+$     RE2 düzenli ifadesi, otomatik sabitlenmez (regex)
+const (
+	urlMatchExact  = "exact"
+	urlMatchPrefix = "prefix"
+	urlMatchGlob   = "glob"
+	urlMatchRegex  = "regex"
+)
+
+// Eşit sayıda sabit karakterde biçimler arasındaki sıra (küçük olan kazanır).
+// Aynı sabit kısımla glob ve regex, altındaki her yolu kapsayan prefix'ten dardır.
+var urlMatchOrder = map[string]int{urlMatchExact: 0, urlMatchGlob: 1, urlMatchRegex: 2, urlMatchPrefix: 3}
+
+// urlPattern: Datasource.URLs'teki bir anahtarın çözümlenmiş hali
+type urlPattern struct {
+	Key  string
+	Kind string
+	path string         // exact ve prefix için karşılaştırılan yol
+	re   *regexp.Regexp // glob ve regex için
+	// Özgüllük: desendeki sabit karakter sayısı
+	literal int
+}
+
+// Anahtarı eşleşme biçimine göre çözümle; regex derlenemezse hata döner
+func parseURLPattern(key string) (*urlPattern, error) {
+	p := &urlPattern{Key: key}
+	switch {
+	case strings.HasPrefix(key, "="):
+		p.Kind, p.path = urlMatchExact, key[1:]
+		p.literal = len(p.path)
+	case strings.HasPrefix(key, "~"):
+		re, err := regexp.Compile(key[1:])
+		if err != nil {
+			return nil, err
+		}
+		tree, _ := syntax.Parse(key[1:], syntax.Perl)
+		p.Kind, p.re, p.literal = urlMatchRegex, re, literalRunes(tree)
+	case strings.Contains(key, "*"):
+		var b strings.Builder
+		b.WriteString("^")
+		for i, part := range strings.Split(key, "**") {
+			if i > 0 {
+				b.WriteString(".*")
+			}
+			b.WriteString(strings.ReplaceAll(regexp.QuoteMeta(part), `\*`, "[^/]*"))
+		}
+		b.WriteString("$")
+		p.Kind, p.re = urlMatchGlob, regexp.MustCompile(b.String())
+		p.literal = len(key) - strings.Count(key, "*")
+	case strings.HasSuffix(key, "/") && key != "/":
+		p.Kind, p.path = urlMatchPrefix, key
+		p.literal = len(key)
+	default:
+		// "/" prefix olsaydı her yola uyar, host eşleşmesini gölgelerdi
+		p.Kind, p.path = urlMatchExact, key
+		p.literal = len(key)
+	}
+	return p, nil
+}
+
+// Düzenli ifadedeki sabit karakter sayısı
+func literalRunes(re *syntax.Regexp) int {
+	if re == nil {
+		return 0
+	}
+	n := 0
+	if re.Op == syntax.OpLiteral {
+		n = len(re.Rune)
+	}
+	for _, sub := range re.Sub {
+		n += literalRunes(sub)
+	}
+	return n
+}
+
+// Yolun desene uyup uymadığı ve uyuyorsa eşleşmenin biçimi (prefix anahtarı yola eşitse exact sayılır)
+func (p *urlPattern) match(path string) (string, bool) {
+	switch p.Kind {
+	case urlMatchExact:
+		return urlMatchExact, path == p.path
+	case urlMatchPrefix:
+		if path == p.path {
+			return urlMatchExact, true
+		}
+		return urlMatchPrefix, strings.HasPrefix(path, p.path)
+	}
+	return p.Kind, p.re.MatchString(path)
+}
+
+// urlMatch: bir pages config'inde yola uyan URL anahtarı
+type urlMatch struct {
+	Config  PagesConfig
+	Pattern *urlPattern
+	Kind    string
+	Ref     interface{}
+}
+
+// a'nın b'den daha özgül (>0), eşit (0) ya da daha genel (<0) olduğu:
+// exact > daha çok sabit karakter > glob/regex/prefix sırası
+func (a *urlMatch) specificity(b *urlMatch) int {
+	if (a.Kind == urlMatchExact) != (b.Kind == urlMatchExact) {
+		if a.Kind == urlMatchExact {
+			return 1
+		}
+		return -1
+	}
+	if a.Pattern.literal != b.Pattern.literal {
+		return a.Pattern.literal - b.Pattern.literal
+	}
+	return urlMatchOrder[b.Kind] - urlMatchOrder[a.Kind]
+}
+
+// resolve'daki url parametresinden yolu al (sorgu ve fragment atılır, tam URL'de yalnız yol)
+func requestPath(raw string) string {
+	if u, err := neturl.Parse(raw); err == nil && u.Path != "" {
+		return u.Path
+	}
+	if i := strings.IndexAny(raw, "?#"); i >= 0 {
+		return raw[:i]
+	}
+	return raw
+}
+
+// Config'te yola uyan en özgül URL anahtarı; eşit özgüllükte anahtar sırası belirler
+func bestURLMatch(cfg PagesConfig, path string) *urlMatch {
+	var best *urlMatch
+	for key, ref := range cfg.Datasource.URLs {
+		p, err := parseURLPattern(key)
+		if err != nil {
+			continue
+		}
+		kind, ok := p.match(path)
+		if !ok {
+			continue
+		}
+		m := &urlMatch{Config: cfg, Pattern: p, Kind: kind, Ref: ref}
+		if best == nil {
+			best = m
+		} else if c := m.specificity(best); c > 0 || c == 0 && key < best.Pattern.Key {
+			best = m
+		}
+	}
+	return best
+}
+
+// Kaydedilen URL anahtarlarındaki regex'ler derlenebilmeli
+func validateURLPatterns(urls map[string]interface{}) error {
+	keys := make([]string, 0, len(urls))
+	for k := range urls {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var errs ValidationErrors
+	for _, k := range keys {
+		if _, err := parseURLPattern(k); err != nil {
+			errs = append(errs, ValidationError{Path: fmt.Sprintf("datasource.urls[%q]", k), Code: codeInvalidRegex, Message: "geçersiz düzenli ifade: " + err.Error()})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestURLPatternMatch(t *testing.T) {
+	tests := []struct {
+		key, path string
+		kind      string
+		match     bool
+	}{
+		{"/post/", "/post/my-article", urlMatchPrefix, true},
+		{"/post/", "/post/", urlMatchExact, true},
+		{"/post/", "/post", urlMatchPrefix, false},
+		{"/archive", "/archive", urlMatchExact, true},
+		{"/archive", "/archive/2024", urlMatchExact, false},
+		{"/archive", "/archives", urlMatchExact, false},
+		{"/", "/", urlMatchExact, true},
+		{"/", "/anything/at/all", urlMatchExact, false},
+		{"=/about", "/about", urlMatchExact, true},
+		{"=/about", "/about/team", urlMatchExact, false},
+		{"/post/*", "/post/my-article", urlMatchGlob, true},
+		{"/post/*", "/post/a/b", urlMatchGlob, false},
+		{"/post/**", "/post/a/b", urlMatchGlob, true},
+		{"/*/edit", "/42/edit", urlMatchGlob, true},
+		{"/a.b/*", "/axb/c", urlMatchGlob, false},
+		{`~^/p/[0-9]+$`, "/p/123", urlMatchRegex, true},
+		{`~^/p/[0-9]+$`, "/p/abc", urlMatchRegex, false},
+	}
+	for _, tt := range tests {
+		p, err := parseURLPattern(tt.key)
+		if err != nil {
+			t.Fatalf("parseURLPattern(%q): %v", tt.key, err)
+		}
+		kind, ok := p.match(tt.path)
+		if ok != tt.match || ok && kind != tt.kind {
+			t.Errorf("%q ~ %q = %s %v, want %s %v", tt.key, tt.path, kind, ok, tt.kind, tt.match)
+		}
+	}
+	if _, err := parseURLPattern("~("); err == nil {
+		t.Error("invalid regex accepted")
+	}
+}
+
+func TestResolveMostSpecificURL(t *testing.T) {
+	s := useMemoryStore(t)
+	putRefConfigs(t, s, "home.yaml", "post.yaml", "comments.yaml", "legacy.yaml", "about.yaml", "list.yaml", "sale.yaml")
+	router := newRouter()
+
+	blog := `{"id": "blog", "name": "Blog", "datasource": {"urls": {"/": "home.yaml", "/post/": "post.yaml",
+		"/post/*/comments": "comments.yaml", "~^/post/[0-9]+$": "legacy.yaml", "=/about": "about.yaml"}}}`
+	shop := `{"id": "shop", "name": "Shop", "datasource": {"urls": {"/products/**": "list.yaml", "/products/sale/": "sale.yaml"}}}`
+	for _, body := range []string{blog, shop} {
+		if w := doRequest(router, "POST", "/api/pages", body, nil); w.Code != http.StatusCreated {
+			t.Fatalf("POST: %d %s", w.Code, w.Body.String())
+		}
+	}
+
+	tests := []struct {
+		url, config, ref, matchedBy, pattern string
+	}{
+		{"/post/my-article", "blog", "post.yaml", "url_prefix", "/post/"},
+		{"/post/my-article/comments?page=2", "blog", "comments.yaml", "url_glob", "/post/*/comments"},
+		{"/post/42", "blog", "legacy.yaml", "url_regex", "~^/post/[0-9]+$"},
+		{"/about", "blog", "about.yaml", "url", "=/about"},
+		{"https://shop.example.com/products/sale/shoes", "shop", "sale.yaml", "url_prefix", "/products/sale/"},
+		{"/products/shoes/red", "shop", "list.yaml", "url_glob", "/products/**"},
+		{"/", "blog", "home.yaml", "url", "/"},
+	}
+	for _, tt := range tests {
+		w := doRequest(router, "GET", "/api/pages/resolve?url="+tt.url, "", nil)
+		var res struct {
+			Config         PagesConfig `json:"config"`
+			MatchedBy      string      `json:"matched_by"`
+			MatchedPattern string      `json:"matched_pattern"`
+			ConfigRef      string      `json:"config_ref"`
+		}
+		if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &res) != nil {
+			t.Errorf("%s: %d %s", tt.url, w.Code, w.Body.String())
+			continue
+		}
+		if res.Config.ID != tt.config || res.ConfigRef != tt.ref || res.MatchedBy != tt.matchedBy || res.MatchedPattern != tt.pattern {
+			t.Errorf("%s: got %s %s %s %s, want %s %s %s %s", tt.url, res.Config.ID, res.ConfigRef, res.MatchedBy, res.MatchedPattern,
+				tt.config, tt.ref, tt.matchedBy, tt.pattern)
+		}
+	}
+
+	// "/" yalnız kök yola uyar; başka yol hiçbir desene uymaz
+	if w := doRequest(router, "GET", "/api/pages/resolve?url=/about/team", "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("/about/team: %d %s", w.Code, w.Body.String())
+	}
+
+	w := doRequest(router, "POST", "/api/pages", `{"id": "bad", "datasource": {"urls": {"~(": "x.yaml"}}}`, nil)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("invalid regex key: %d %s", w.Code, w.Body.String())
+	}
+}
+
+// configs/ altındaki örnek pages dosyaları ve testdata/refs altındaki referans dosyalarıyla:
+// blog'un "/" anahtarı başka hostun yollarını yakalamamalı
+func TestResolveShippedFixtures(t *testing.T) {
+	s := useMemoryStore(t)
+	pages, err := filepath.Glob(filepath.Join(configDir, "pages_*.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	refs, err := filepath.Glob(filepath.Join("testdata", "refs", "*.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range append(pages, refs...) {
+		name := strings.TrimSuffix(filepath.Base(f), ".yaml")
+		kind := KindGeneral
+		if id := strings.TrimPrefix(name, "pages_"); id != name {
+			kind, name = KindPages, id
+		}
+		data, err := ioutil.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Put(kind, name, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	router := newRouter()
+
+	tests := []struct {
+		query, config, ref, matchedBy string
+	}{
+		{"host=shop.example.com&url=/deals", "ecommerce", "ecommerce_main.yaml", "host"},
+		{"host=shop.example.com&url=/contact", "ecommerce", "ecommerce_main.yaml", "host"},
+		{"host=shop.example.com&url=/cart", "ecommerce", "ecommerce_cart.yaml", "url"},
+		{"host=blog.example.com&url=/", "blog", "blog_home.yaml", "url"},
+		{"host=blog.example.com&url=/post/hello", "blog", "blog_post.yaml", "url_prefix"},
+		{"host=blog.example.com&url=/contact", "blog", "blog_main.yaml", "host"},
+		{"url=/", "blog", "blog_home.yaml", "url"},
+	}
+	for _, tt := range tests {
+		w := doRequest(router, "GET", "/api/pages/resolve?"+tt.query, "", nil)
+		var res struct {
+			Config    PagesConfig `json:"config"`
+			MatchedBy string      `json:"matched_by"`
+			ConfigRef string      `json:"config_ref"`
+		}
+		if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &res) != nil {
+			t.Errorf("%s: %d %s", tt.query, w.Code, w.Body.String())
+			continue
+		}
+		if res.Config.ID != tt.config || res.ConfigRef != tt.ref || res.MatchedBy != tt.matchedBy {
+			t.Errorf("%s: got %s %s %s, want %s %s %s", tt.query, res.Config.ID, res.ConfigRef, res.MatchedBy, tt.config, tt.ref, tt.matchedBy)
+		}
+	}
+}
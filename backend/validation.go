@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// Doğrulama hata kodları
+const (
+	codeRequired          = "required"
+	codeInvalidType       = "invalid_type"
+	codeUnknownField      = "unknown_field"
+	codeUnknownActionType = "unknown_action_type"
+	codeInvalidEnum       = "invalid_enum"
+	codeInvalidSelector   = "invalid_selector"
+	codeUnknownPolicy     = "unknown_policy"
+	codeAmbiguousPolicy   = "ambiguous_policy"
+	codeForbiddenValue    = "forbidden_value"
+	codeUnsafeHTML        = "unsafe_html"
+	codeInvalidRegex      = "invalid_regex"
+	codeUnsafeRegex       = "unsafe_regex"
+	codeUnknownVariable   = "unknown_variable"
+	codeInvalidTemplate   = "invalid_template"
+	codeInvalidFormat     = "invalid_format"
+	codeMissingRef        = "missing_ref"
+	codeExtendsCycle      = "extends_cycle"
+	codeUnknownAction     = "unknown_action"
+)
+
+// ValidationError: gönderilen config'teki tek bir sorun.
+// Path JSON yoludur (ör. "actions[3].selector").
+type ValidationError struct {
+	Path    string `json:"path"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors: bir istekte bulunan tüm doğrulama sorunları
+type ValidationErrors []ValidationError
+
+func (errs ValidationErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Path + ": " + e.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Hatayı ValidationErrors'a çevir; doğrulama hatası değilse false döner
+func asValidationErrors(err error) (ValidationErrors, bool) {
+	var verrs ValidationErrors
+	if errors.As(err, &verrs) {
+		return verrs, true
+	}
+	var aerrs actionErrors
+	if errors.As(err, &aerrs) {
+		return aerrs.validationErrors(), true
+	}
+	return nil, false
+}
+
+// Doğrulama sorunlarını 422 olarak yaz
+func writeValidationErrors(w http.ResponseWriter, errs ValidationErrors) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":  "Config doğrulanamadı",
+		"errors": errs,
+	})
+}
+
+// err bir doğrulama hatasıysa 422 yazar ve true döner
+func writeValidationError(w http.ResponseWriter, err error) bool {
+	verrs, ok := asValidationErrors(err)
+	if ok {
+		writeValidationErrors(w, verrs)
+	}
+	return ok
+}
+
+// Tek bir alan için doğrulama hatası yaz
+func writeFieldError(w http.ResponseWriter, path, code, message string) {
+	writeValidationErrors(w, ValidationErrors{{Path: path, Code: code, Message: message}})
+}
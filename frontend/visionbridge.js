@@ -37,18 +37,46 @@
         }
       });
     },
-    alter: ({ oldValue, newValue }) => {
+    alter: ({ oldValue, newValue, regex, caseSensitive }) => {
+      const value = expandTemplate(newValue);
+      // regex: true ise oldValue sunucuda doğrulanmış bir RegExp desenidir
+      let pattern = null;
+      if (regex) {
+        pattern = new RegExp(oldValue, caseSensitive === false ? "gi" : "g");
+      } else if (caseSensitive === false) {
+        pattern = new RegExp(oldValue.replace(/[.*+?^${}()|[\]\\]/g, "\\$&"), "gi");
+      }
       // Tüm metin düğümlerinde değiştir
       const treeWalker = document.createTreeWalker(document.body, NodeFilter.SHOW_TEXT);
       let node;
       while ((node = treeWalker.nextNode())) {
-        if (node.nodeValue.includes(oldValue)) {
-          node.nodeValue = node.nodeValue.replaceAll(oldValue, newValue);
+        const replaced = pattern
+          ? node.nodeValue.replace(pattern, value)
+          : node.nodeValue.replaceAll(oldValue, value);
+        if (replaced !== node.nodeValue) {
+          node.nodeValue = replaced;
         }
       }
     },
   };
 
+  // alter newValue içindeki {{DEĞİŞKEN}}'ler (sunucudaki templateVariables ile aynı set)
+  const templateVariables = {
+    USER_NAME: () => localStorage.getItem("userName") || "",
+    HOST: () => window.location.hostname,
+    PATH: () => window.location.pathname,
+    URL: () => window.location.href,
+    PAGE_TITLE: () => document.title,
+    DATE: () => new Date().toLocaleDateString(),
+    YEAR: () => String(new Date().getFullYear()),
+  };
+
+  function expandTemplate(value) {
+    return value.replace(/\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}/g, (match, name) =>
+      templateVariables[name] ? templateVariables[name]() : match
+    );
+  }
+
   // Analytics: aksiyon sayaçları ve log
   const analytics = {
     counts: {},